@@ -0,0 +1,216 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	backupDirname    = "backups"
+	backupPrefix     = "config-"
+	backupSuffix     = ".yaml"
+	backupTimeFormat = "20060102-150405.000"
+
+	// DefaultBackupRetention is the number of backups kept,
+	// when a config does not specify a retention.
+	DefaultBackupRetention = 10
+)
+
+// Common backup errors.
+var (
+	ErrBackupIDEmpty  = errors.New("backup: id is empty")
+	ErrBackupNotFound = errors.New("backup: not found")
+)
+
+// now is used to get the current time, and is replaced in tests.
+var now = time.Now
+
+// Backup is a copy of the config file, taken before it was overwritten.
+type Backup struct {
+	// ID is the unique identifier of the backup, derived from its time.
+	ID   string
+	Time time.Time
+
+	path string
+}
+
+// backupRetention returns the number of backups to keep. A negative
+// BackupRetention disables backups, and zero uses the default.
+func (c *Config) backupRetention() int {
+	if c.BackupRetention == 0 {
+		return DefaultBackupRetention
+	}
+
+	return c.BackupRetention
+}
+
+// backup copies the current config file into the backups directory, before
+// removing the oldest backups, to keep within the config's retention. If
+// the config file does not exist, or backups are disabled, nothing is done.
+func (c *Config) backup() error {
+	retention := c.backupRetention()
+	if retention < 0 {
+		return nil
+	}
+
+	filePath := path.Join(c.configDir, configFilename)
+	exists, err := c.fs.FileExists(filePath)
+	if err != nil || !exists {
+		return err
+	}
+
+	data, err := c.fs.Read(filePath)
+	if err != nil {
+		return err
+	}
+
+	backupDir := path.Join(c.configDir, backupDirname)
+	err = c.fs.EnsureDirectory(backupDir)
+	if err != nil {
+		return err
+	}
+
+	id := now().UTC().Format(backupTimeFormat)
+	err = c.fs.Write(path.Join(backupDir, backupPrefix+id+backupSuffix), data)
+	if err != nil {
+		return err
+	}
+
+	backups, err := c.Backups()
+	if err != nil {
+		return err
+	}
+
+	for i := retention; i < len(backups); i++ {
+		err = c.fs.Remove(backups[i].path)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Backups returns the backups of the config file, newest first.
+func (c *Config) Backups() ([]*Backup, error) {
+	backupDir := path.Join(c.configDir, backupDirname)
+	names, err := c.fs.List(backupDir)
+	if err != nil {
+		if errors.Is(err, ErrDirNotExists) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var backups []*Backup
+	for _, name := range names {
+		if !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupSuffix) {
+			continue
+		}
+
+		id := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupSuffix)
+		t, err := time.Parse(backupTimeFormat, id)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, &Backup{
+			ID:   id,
+			Time: t,
+			path: path.Join(backupDir, name),
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Time.After(backups[j].Time)
+	})
+
+	return backups, nil
+}
+
+// RestoreBackup replaces the config file with the backup with the given
+// id, then reloads the config. The current config file is backed up first,
+// so a restore can be undone. If the backup does not exist,
+// ErrBackupNotFound is returned, and if it can't be migrated or isn't
+// valid, the config file is left unchanged.
+func (c *Config) RestoreBackup(id string) error {
+	if id == "" {
+		return ErrBackupIDEmpty
+	}
+
+	unlock, err := c.fs.Lock(path.Join(c.configDir, configLockFilename))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	backups, err := c.Backups()
+	if err != nil {
+		return err
+	}
+
+	var b *Backup
+	for _, backup := range backups {
+		if backup.ID == id {
+			b = backup
+			break
+		}
+	}
+
+	if b == nil {
+		return ErrBackupNotFound
+	}
+
+	data, err := c.fs.Read(b.path)
+	if err != nil {
+		return err
+	}
+
+	doc, _, err := c.decode(b.path, data)
+	if err != nil {
+		return err
+	}
+
+	err = yaml.Unmarshal(doc, &map[string]interface{}{})
+	if err != nil {
+		return fmt.Errorf("backup: %s could not be parsed: %w", id, err)
+	}
+
+	// The backup is migrated and validated into a read-only copy of the
+	// config first, so an invalid backup never replaces the config file.
+	restored := &Config{configDir: c.configDir, fs: c.fs, cp: c.cp, readOnly: true}
+	doc, _, err = restored.migrate(b.path, doc)
+	if err != nil {
+		return fmt.Errorf("backup: %s could not be restored: %w", id, err)
+	}
+
+	err = yaml.Unmarshal(doc, restored)
+	if err != nil {
+		return fmt.Errorf("backup: %s could not be parsed: %w", id, err)
+	}
+
+	err = restored.Validate()
+	if err != nil {
+		return fmt.Errorf("backup: %s could not be restored: %w", id, err)
+	}
+
+	err = c.backup()
+	if err != nil {
+		return err
+	}
+
+	err = c.fs.Write(path.Join(c.configDir, configFilename), data)
+	if err != nil {
+		return err
+	}
+
+	return c.load()
+}
@@ -0,0 +1,232 @@
+package passport
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func setNow(t *testing.T, tm time.Time) {
+	oldNow := now
+	now = func() time.Time { return tm }
+
+	t.Cleanup(func() {
+		now = oldNow
+	})
+}
+
+func TestConfig_backup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+	testBackupDir := path.Join(testDir, backupDirname)
+	testData := []byte("version: 1\nsecrets: []\n")
+
+	setNow(t, time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC))
+
+	t.Run("Where Config File Does Not Exist", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(false, nil)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.backup()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Backups Are Disabled", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+
+		c := &Config{configDir: testDir, fs: fs, BackupRetention: -1}
+		err := c.backup()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Removes Backups Beyond Retention", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().FileExists(testFilePath).Return(true, nil),
+			fs.EXPECT().Read(testFilePath).Return(testData, nil),
+			fs.EXPECT().EnsureDirectory(testBackupDir).Return(nil),
+			fs.EXPECT().Write(path.Join(testBackupDir, "config-20210501-123015.000.yaml"), testData).Return(nil),
+			fs.EXPECT().List(testBackupDir).Return([]string{
+				"config-20210430-090000.000.yaml",
+				"config-20210501-123015.000.yaml",
+				"config-20210429-090000.000.yaml",
+			}, nil),
+			fs.EXPECT().Remove(path.Join(testBackupDir, "config-20210429-090000.000.yaml")).Return(nil),
+		)
+
+		c := &Config{configDir: testDir, fs: fs, BackupRetention: 2}
+		err := c.backup()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Write Fails", func(t *testing.T) {
+		testErr := errors.New("fs: error")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(true, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+		fs.EXPECT().EnsureDirectory(testBackupDir).Return(nil)
+		fs.EXPECT().Write(gomock.Any(), testData).Return(testErr)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.backup()
+		assert.Equal(t, testErr, err)
+	})
+}
+
+func TestConfig_Backups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testBackupDir := path.Join(testDir, backupDirname)
+
+	t.Run("Returns Backups Newest First", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().List(testBackupDir).Return([]string{
+			"config-20210429-090000.000.yaml",
+			"notes.txt",
+			"config-invalid.yaml",
+			"config-20210501-123015.500.yaml",
+		}, nil)
+
+		c := &Config{configDir: testDir, fs: fs}
+		backups, err := c.Backups()
+		assert.NoError(t, err)
+		assert.Equal(t, []*Backup{
+			{
+				ID:   "20210501-123015.500",
+				Time: time.Date(2021, 5, 1, 12, 30, 15, 500000000, time.UTC),
+				path: path.Join(testBackupDir, "config-20210501-123015.500.yaml"),
+			},
+			{
+				ID:   "20210429-090000.000",
+				Time: time.Date(2021, 4, 29, 9, 0, 0, 0, time.UTC),
+				path: path.Join(testBackupDir, "config-20210429-090000.000.yaml"),
+			},
+		}, backups)
+	})
+
+	t.Run("Where Backup Directory Does Not Exist", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().List(testBackupDir).Return(nil, ErrDirNotExists)
+
+		c := &Config{configDir: testDir, fs: fs}
+		backups, err := c.Backups()
+		assert.NoError(t, err)
+		assert.Nil(t, backups)
+	})
+
+	t.Run("Where List Fails", func(t *testing.T) {
+		testErr := errors.New("fs: error")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().List(testBackupDir).Return(nil, testErr)
+
+		c := &Config{configDir: testDir, fs: fs}
+		backups, err := c.Backups()
+		assert.Nil(t, backups)
+		assert.Equal(t, testErr, err)
+	})
+}
+
+func TestConfig_RestoreBackup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+	testLockPath := path.Join(testDir, configLockFilename)
+	testBackupDir := path.Join(testDir, backupDirname)
+	testBackupPath := path.Join(testBackupDir, "config-20210429-090000.000.yaml")
+	testData := []byte("version: 1\nsecrets:\n- name: MySecret\n  value: abc\nworkspaces: []\n")
+	unlock := func() error { return nil }
+
+	setNow(t, time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC))
+
+	t.Run("Given Valid ID", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().Lock(testLockPath).Return(unlock, nil),
+			fs.EXPECT().List(testBackupDir).Return([]string{"config-20210429-090000.000.yaml"}, nil),
+			fs.EXPECT().Read(testBackupPath).Return(testData, nil),
+			fs.EXPECT().FileExists(testFilePath).Return(true, nil),
+			fs.EXPECT().Read(testFilePath).Return([]byte("version: 1\nsecrets: []\n"), nil),
+			fs.EXPECT().EnsureDirectory(testBackupDir).Return(nil),
+			fs.EXPECT().Write(path.Join(testBackupDir, "config-20210501-123015.000.yaml"), gomock.Any()).Return(nil),
+			fs.EXPECT().List(testBackupDir).Return([]string{
+				"config-20210429-090000.000.yaml",
+				"config-20210501-123015.000.yaml",
+			}, nil),
+			fs.EXPECT().Write(testFilePath, testData).Return(nil),
+			fs.EXPECT().Read(testFilePath).Return(testData, nil),
+		)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.RestoreBackup("20210429-090000.000")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", c.Secrets[0].Value)
+	})
+
+	t.Run("Given Empty ID", func(t *testing.T) {
+		c := &Config{configDir: testDir, fs: mock.NewMockFilesys(ctrl)}
+		err := c.RestoreBackup("")
+		assert.Equal(t, ErrBackupIDEmpty, err)
+	})
+
+	t.Run("Where Backup Does Not Exist", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().List(testBackupDir).Return([]string{"config-20210429-090000.000.yaml"}, nil)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.RestoreBackup("20210430-090000.000")
+		assert.Equal(t, ErrBackupNotFound, err)
+	})
+
+	t.Run("Where Backup Is Invalid", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().List(testBackupDir).Return([]string{"config-20210429-090000.000.yaml"}, nil)
+		fs.EXPECT().Read(testBackupPath).Return([]byte("secrets: [\n"), nil)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.RestoreBackup("20210429-090000.000")
+		assert.Contains(t, err.Error(), "backup: 20210429-090000.000 could not be parsed")
+	})
+
+	t.Run("Where Backup Fails Validation", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().List(testBackupDir).Return([]string{"config-20210429-090000.000.yaml"}, nil)
+		fs.EXPECT().Read(testBackupPath).Return([]byte("version: 1\nworkspaces:\n- name: api\n"), nil)
+		fs.EXPECT().Write(gomock.Any(), gomock.Any()).Times(0)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.RestoreBackup("20210429-090000.000")
+		assert.ErrorIs(t, err, ErrWorkspacePathEmpty)
+		assert.Contains(t, err.Error(), "backup: 20210429-090000.000 could not be restored")
+	})
+
+	t.Run("Where Backup Has Unsupported Version", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().List(testBackupDir).Return([]string{"config-20210429-090000.000.yaml"}, nil)
+		fs.EXPECT().Read(testBackupPath).Return([]byte("version: 99\n"), nil)
+		fs.EXPECT().Write(gomock.Any(), gomock.Any()).Times(0)
+
+		c := &Config{configDir: testDir, fs: fs}
+		err := c.RestoreBackup("20210429-090000.000")
+		assert.ErrorIs(t, err, ErrConfigVersionUnsupported)
+	})
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/reecerussell/passport"
+)
+
+var backupsCommand = &passport.Command{
+	Name:        "backups",
+	Description: "provides commands used to manage backups of the config file",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		retention := cmd.Args.String("retention")
+		if retention == "" {
+			cmd.Help()
+			return nil
+		}
+
+		n, err := strconv.Atoi(retention)
+		if err != nil {
+			return fmt.Errorf("backups: retention must be a number: %v", err)
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			c.BackupRetention = n
+			return nil
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "retention",
+			Description: "optionally, sets the number of backups to keep, where -1 disables backups",
+		},
+	},
+	Cmds: passport.CommandSet{
+		listBackupsCommand,
+	},
+}
+
+var listBackupsCommand = &passport.Command{
+	Name:        "ls",
+	Description: "used to list backups of the config file, newest first",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		backups, err := cnf.Backups()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Backups:")
+
+		for _, b := range backups {
+			fmt.Printf("> %s (%s)\n", b.ID, b.Time.Local().Format("2006-01-02 15:04:05"))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var checkConfigCommand = &passport.Command{
+	Name:        "check",
+	Description: "used to check the config file, and the commands of scripts, for errors",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		invalid := 0
+		for _, w := range cnf.Workspaces {
+			for _, s := range w.Scripts {
+				err = s.Validate()
+				if err != nil {
+					fmt.Printf("%s > %s:\n%v\n", w.Name, s.Name, err)
+					invalid++
+				}
+			}
+		}
+
+		if invalid > 0 {
+			return fmt.Errorf("config: found %d invalid script(s)", invalid)
+		}
+
+		fmt.Println("Config is valid!")
+
+		return nil
+	},
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+// Command is the main entrypoint command for operations around the config file.
+var Command = &passport.Command{
+	Name:        "config",
+	Description: "provides commands used to manage the config file",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cmd.Help()
+		return nil
+	},
+	Cmds: passport.CommandSet{
+		checkConfigCommand,
+		backupsCommand,
+		restoreConfigCommand,
+		encryptConfigCommand,
+		decryptConfigCommand,
+		listStoresCommand,
+	},
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var decryptConfigCommand = &passport.Command{
+	Name:        "decrypt",
+	Description: "used to decrypt the config file, so it is stored in plain text",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = cnf.SetEncrypted(false)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Successfully decrypted the config!")
+
+		return nil
+	},
+}
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var encryptConfigCommand = &passport.Command{
+	Name:        "encrypt",
+	Description: "used to encrypt the entire config file at rest",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = cnf.SetEncrypted(true)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Successfully encrypted the config!")
+
+		return nil
+	},
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var listStoresCommand = &passport.Command{
+	Name:        "stores",
+	Description: "used to list the config stores, by precedence, highest first",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		fmt.Println("Stores:")
+
+		for _, s := range ctx.Stores {
+			selected := ""
+			if s.Name == ctx.Store {
+				selected = " (selected)"
+			}
+
+			fmt.Printf("> %s: %s%s\n", s.Name, s.Dir, selected)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var restoreConfigCommand = &passport.Command{
+	Name:        "restore",
+	Description: "used to restore the config file from a backup, i.e. passport config restore <id>",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("restore: no backup id specified")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = cnf.RestoreBackup(cmd.Params[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Successfully restored the config!")
+
+		return nil
+	},
+}
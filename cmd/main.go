@@ -4,9 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"runtime"
+	"strings"
 
 	"github.com/reecerussell/passport"
+	"github.com/reecerussell/passport/cmd/config"
+	"github.com/reecerussell/passport/cmd/profiles"
 	"github.com/reecerussell/passport/cmd/secrets"
+	"github.com/reecerussell/passport/cmd/vars"
+	"github.com/reecerussell/passport/cmd/vault"
 	"github.com/reecerussell/passport/cmd/workspaces"
 )
 
@@ -16,23 +22,43 @@ var (
 )
 
 func main() {
-	userConfigDir, _ := os.UserConfigDir()
-	if configDir == "" {
-		configDir = path.Join(userConfigDir, ".passport")
+	args, dir := globalArg(os.Args[1:], "config-dir")
+	args, teamDir := globalArg(args, "team-config-dir")
+	args, store := globalArg(args, "store")
+
+	stores := configStores(dir, teamDir)
+	if store == "" {
+		store = os.Getenv("PASSPORT_STORE")
+	}
+
+	if store == "" {
+		store = passport.StorePersonal
 	}
 
-	if len(os.Args) <= 1 {
+	if len(args) < 1 {
 		fmt.Printf("Passport, %s\n", version)
 		os.Exit(0)
 	}
 
+	storeDir := ""
+	for _, s := range stores {
+		if s.Name == store {
+			storeDir = s.Dir
+		}
+	}
+
+	if storeDir == "" {
+		fmt.Fprintf(os.Stderr, "%v: %s\n", passport.ErrStoreNotFound, store)
+		os.Exit(1)
+	}
+
 	fs := passport.NewFilesys()
-	err := fs.EnsureDirectory(configDir)
+	err := fs.EnsureDirectory(storeDir)
 	if err != nil {
 		panic(err)
 	}
 
-	err = passport.EnsureConfigFile(configDir, fs)
+	err = passport.EnsureConfigFile(storeDir, fs)
 	if err != nil {
 		panic(err)
 	}
@@ -41,23 +67,134 @@ func main() {
 		secrets.Command,
 		workspaces.ScriptsCommand,
 		workspaces.RunScriptCommand,
+		workspaces.EnvCommand,
+		profiles.Command,
+		vars.Command,
+		vault.Command,
+		config.Command,
 	}
 
-	cmd := sets.ParseCommand(os.Args[1:])
+	cmd := sets.ParseCommand(args)
 	if cmd == nil {
 		fmt.Println("Command not found!")
 		sets.Help()
 		os.Exit(1)
 	}
 
+	cp := passport.NewLazyCryptoProvider(func() (passport.CryptoProvider, error) {
+		return cryptoProvider(fs)
+	})
+
 	ctx := &passport.CommandContext{
-		ConfigDir: configDir,
-		Crypto:    passport.NewCryptoProvider(),
+		ConfigDir: storeDir,
+		Crypto:    cp,
 		Fs:        passport.NewFilesys(),
+		Stores:    stores,
+		Store:     store,
 	}
 
 	err = cmd.Execute(cmd, ctx)
 	if err != nil {
-		panic(err)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// configStores returns the config stores, ordered by precedence. The
+// personal store's directory is taken from dir, PASSPORT_CONFIG_DIR,
+// the directory set at build time, or the user's config directory,
+// in that order. The team store is only used if its directory is given
+// by teamDir or PASSPORT_TEAM_CONFIG_DIR.
+func configStores(dir, teamDir string) []*passport.Store {
+	if dir == "" {
+		dir = os.Getenv("PASSPORT_CONFIG_DIR")
+	}
+
+	if dir == "" {
+		dir = configDir
+	}
+
+	if dir == "" {
+		userConfigDir, _ := os.UserConfigDir()
+		dir = path.Join(userConfigDir, ".passport")
+	}
+
+	stores := []*passport.Store{
+		{Name: passport.StorePersonal, Dir: dir},
 	}
+
+	if teamDir == "" {
+		teamDir = os.Getenv("PASSPORT_TEAM_CONFIG_DIR")
+	}
+
+	if teamDir != "" {
+		stores = append(stores, &passport.Store{Name: passport.StoreTeam, Dir: teamDir})
+	}
+
+	systemDir := "/etc/passport"
+	if runtime.GOOS == "windows" {
+		systemDir = path.Join(os.Getenv("ProgramData"), "passport")
+	}
+
+	return append(stores, &passport.Store{Name: passport.StoreSystem, Dir: systemDir})
+}
+
+// cryptoProvider returns the CryptoProvider selected by PASSPORT_CRYPTO.
+// By default, values are encrypted with a key derived from the host. With
+// "age", values are encrypted to the identities in PASSPORT_AGE_IDENTITY,
+// and the public keys in PASSPORT_AGE_RECIPIENTS, and with "age-passphrase",
+// the passphrase in PASSPORT_AGE_PASSPHRASE, or entered at a prompt, is used.
+// It's called when a value is first encrypted, or decrypted, so commands
+// which don't need it aren't prompted.
+func cryptoProvider(fs passport.Filesys) (passport.CryptoProvider, error) {
+	switch v := os.Getenv("PASSPORT_CRYPTO"); v {
+	case "", "host":
+		return passport.NewCryptoProvider(), nil
+	case "age":
+		var recipients []string
+		if v := os.Getenv("PASSPORT_AGE_RECIPIENTS"); v != "" {
+			recipients = strings.Split(v, ",")
+		}
+
+		identity := os.Getenv("PASSPORT_AGE_IDENTITY")
+		if identity == "" {
+			return nil, fmt.Errorf("%w, set PASSPORT_AGE_IDENTITY to the path of one", passport.ErrAgeIdentityFile)
+		}
+
+		return passport.LoadAgeCryptoProvider(identity, recipients, fs)
+	case "age-passphrase":
+		passphrase := os.Getenv("PASSPORT_AGE_PASSPHRASE")
+		if passphrase == "" {
+			var err error
+			passphrase, err = passport.ReadPassphrase(os.Stdin, os.Stderr)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return passport.NewAgePassphraseCryptoProvider(passphrase)
+	default:
+		return nil, fmt.Errorf("PASSPORT_CRYPTO must be host, age or age-passphrase, not %q", v)
+	}
+}
+
+// globalArg removes the argument, name, and its value from args, so they
+// aren't parsed by commands. The value is returned, with the remaining args.
+func globalArg(args []string, name string) ([]string, string) {
+	for i, arg := range args {
+		if arg != "--"+name {
+			continue
+		}
+
+		value := ""
+		rest := append([]string{}, args[:i]...)
+		if i+1 < len(args) {
+			value = args[i+1]
+			rest = append(rest, args[i+2:]...)
+		}
+
+		return rest, value
+	}
+
+	return args, ""
 }
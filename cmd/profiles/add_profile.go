@@ -0,0 +1,32 @@
+package profiles
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+var addProfileCommand = &passport.Command{
+	Name:        "add",
+	Description: "used to add a profile",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			set, err := profileSet(c, cmd.Args.Bool("workspace"))
+			if err != nil {
+				return err
+			}
+
+			return set.Add(cmd.Args.String("name"))
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the profile",
+		},
+		workspaceArg(),
+	},
+}
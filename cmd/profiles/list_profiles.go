@@ -0,0 +1,34 @@
+package profiles
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var listProfilesCommand = &passport.Command{
+	Name:        "ls",
+	Description: "used to list all profiles",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		set, err := profileSet(cnf, cmd.Args.Bool("workspace"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Profiles:")
+
+		for _, p := range *set {
+			fmt.Printf("> %s\n", p.Name)
+		}
+
+		return nil
+	},
+	Args: passport.CommandArgs{
+		workspaceArg(),
+	},
+}
@@ -0,0 +1,100 @@
+package profiles
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/reecerussell/passport"
+)
+
+// Command is the main entrypoint command for operations around profiles.
+var Command = &passport.Command{
+	Name:        "profiles",
+	Description: "provides commands used to manage and view profiles",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := cmd.Args.String("name")
+		if name == "" {
+			cmd.Help()
+			return nil
+		}
+
+		set, err := profileSet(cnf, cmd.Args.Bool("workspace"))
+		if err != nil {
+			return err
+		}
+
+		p, err := set.Get(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Name: %s\n", p.Name)
+		fmt.Println("Vars:")
+
+		for _, k := range sortedKeys(p.Vars) {
+			fmt.Printf("> %s=%s\n", k, p.Vars[k])
+		}
+
+		fmt.Println("Secrets:")
+
+		for _, k := range sortedKeys(p.Secrets) {
+			fmt.Printf("> %s -> %s\n", k, p.Secrets[k])
+		}
+
+		return nil
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "optionally, a name can be passed in to get a single profile",
+		},
+		workspaceArg(),
+	},
+	Cmds: passport.CommandSet{
+		listProfilesCommand,
+		addProfileCommand,
+		removeProfileCommand,
+		setProfileCommand,
+	},
+}
+
+func workspaceArg() *passport.CommandArg {
+	return &passport.CommandArg{
+		Name:        "workspace",
+		Description: "determines whether to use the current workspace's profiles, rather than global ones",
+		IsFlag:      true,
+	}
+}
+
+// profileSet returns a pointer to the global profile set, or the
+// profile set of the workspace in the current working directory.
+func profileSet(cnf *passport.Config, workspace bool) (*passport.ProfileSet, error) {
+	if !workspace {
+		return &cnf.Profiles, nil
+	}
+
+	wd, _ := os.Getwd()
+	w, err := cnf.GetWorkspace(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &w.Profiles, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,32 @@
+package profiles
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+var removeProfileCommand = &passport.Command{
+	Name:        "rm",
+	Description: "used to remove a profile",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			set, err := profileSet(c, cmd.Args.Bool("workspace"))
+			if err != nil {
+				return err
+			}
+
+			return set.Remove(cmd.Args.String("name"))
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the profile to remove",
+		},
+		workspaceArg(),
+	},
+}
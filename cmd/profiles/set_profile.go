@@ -0,0 +1,78 @@
+package profiles
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/reecerussell/passport"
+)
+
+var setProfileCommand = &passport.Command{
+	Name:        "set",
+	Description: "used to set a variable, or map a secret, on a profile",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			set, err := profileSet(c, cmd.Args.Bool("workspace"))
+			if err != nil {
+				return err
+			}
+
+			p, err := set.Get(cmd.Args.String("name"))
+			if err != nil {
+				return err
+			}
+
+			if v := cmd.Args.String("var"); v != "" {
+				k, v, err := splitPair(v)
+				if err != nil {
+					return err
+				}
+
+				err = p.SetVar(k, v)
+				if err != nil {
+					return err
+				}
+			}
+
+			if v := cmd.Args.String("secret"); v != "" {
+				ref, name, err := splitPair(v)
+				if err != nil {
+					return err
+				}
+
+				return p.MapSecret(ref, name)
+			}
+
+			return nil
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the profile",
+		},
+		{
+			Name:        "var",
+			Description: "a variable to set on the profile, in the format NAME=VALUE",
+		},
+		{
+			Name:        "secret",
+			Description: "a secret to map on the profile, in the format REFERENCE=SECRET_NAME",
+		},
+		workspaceArg(),
+	},
+}
+
+func splitPair(s string) (string, string, error) {
+	i := strings.Index(s, "=")
+	if i < 1 {
+		return "", "", errors.New("profiles: expected a value in the format KEY=VALUE")
+	}
+
+	return s[:i], s[i+1:], nil
+}
@@ -8,35 +8,56 @@ var addSecretCommand = &passport.Command{
 	Name:        "add",
 	Description: "used to add a secret",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
 		if err != nil {
 			return err
 		}
 
 		name := cmd.Args.String("name")
-		value := cmd.Args.String("value")
+		err = cnf.CheckNewSecret(name)
+		if err != nil {
+			return err
+		}
+
 		plainText := cmd.Args.Bool("plain-text")
+		source := cmd.Args.String("source")
+		if source != "" {
+			return cnf.Update(func(c *passport.Config) error {
+				return c.AddSecretSource(name, source, cmd.Args.String("timeout"))
+			})
+		}
 
-		err = cnf.AddSecret(name, value, !plainText, ctx.Crypto)
+		value, err := secretValue(cmd.Args)
 		if err != nil {
 			return err
 		}
 
-		return cnf.Save()
+		return cnf.Update(func(c *passport.Config) error {
+			return c.AddSecret(name, value, !plainText, ctx.Crypto)
+		})
 	},
-	Args: passport.CommandArgs{
+	Args: append(passport.CommandArgs{
 		{
 			Name:        "name",
 			Description: "the name of the secret",
 		},
-		{
-			Name:        "value",
-			Description: "the value of the secret",
-		},
 		{
 			Name:        "plain-text",
 			Description: "determines whether the value should be stored in plain text",
 			IsFlag:      true,
 		},
-	},
+		{
+			Name:        "source",
+			Description: "optionally, where to read the value from when used, in place of a value, i.e. file:~/token or exec:aws ecr get-login-password",
+		},
+		{
+			Name:        "timeout",
+			Description: "optionally, the time an exec source is given to run, i.e. 10s, defaults to 30s",
+		},
+	}, append(valueArgs(), scopeArgs()...)...),
 }
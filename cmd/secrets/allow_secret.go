@@ -0,0 +1,50 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/reecerussell/passport"
+)
+
+var allowSecretCommand = &passport.Command{
+	Name:        "allow",
+	Description: "used to allow the current workspace, or one of its scripts, to use a secret, i.e. passport secrets allow <name> --script deploy",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("allow: no secret name specified")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		w, err := currentWorkspace(cnf)
+		if err != nil {
+			return err
+		}
+
+		script := cmd.Args.String("script")
+		if script != "" {
+			_, err = w.GetScript(script)
+			if err != nil {
+				return err
+			}
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.AllowSecret(cmd.Params[0], w.Name, script)
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "script",
+			Description: "optionally, the name of the script to allow, otherwise all of the workspace's scripts are allowed",
+		},
+	}, scopeArgs()...),
+}
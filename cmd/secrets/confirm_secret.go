@@ -0,0 +1,38 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/reecerussell/passport"
+)
+
+var confirmSecretCommand = &passport.Command{
+	Name:        "confirm",
+	Description: "used to make scripts ask before using a secret, i.e. passport secrets confirm <name>",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("confirm: no secret name specified")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.SetSecretConfirm(cmd.Params[0], !cmd.Args.Bool("off"))
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "off",
+			Description: "determines whether scripts should stop asking before using the secret",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
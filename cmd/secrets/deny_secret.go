@@ -0,0 +1,42 @@
+package secrets
+
+import (
+	"errors"
+
+	"github.com/reecerussell/passport"
+)
+
+var denySecretCommand = &passport.Command{
+	Name:        "deny",
+	Description: "used to remove the current workspace, or one of its scripts, from the workspaces allowed to use a secret",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("deny: no secret name specified")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		w, err := currentWorkspace(cnf)
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.DenySecret(cmd.Params[0], w.Name, cmd.Args.String("script"))
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "script",
+			Description: "optionally, the name of the script to remove",
+		},
+	}, scopeArgs()...),
+}
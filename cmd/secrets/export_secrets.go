@@ -0,0 +1,56 @@
+package secrets
+
+import (
+	"os"
+	"strings"
+
+	"github.com/reecerussell/passport"
+)
+
+var exportSecretsCommand = &passport.Command{
+	Name:        "export",
+	Description: "used to print the values of secrets, i.e. passport secrets export --format dotenv > .env",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		var patterns []string
+		if filter := cmd.Args.String("filter"); filter != "" {
+			patterns = strings.Split(filter, ",")
+		}
+
+		values, err := cnf.ExportSecrets(patterns, cmd.Args.Bool("force"), ctx.Crypto)
+		if err != nil {
+			return err
+		}
+
+		format := cmd.Args.String("format")
+		if format == "" {
+			format = passport.FormatDotenv
+		}
+
+		return passport.FormatSecrets(os.Stdout, values, format)
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "format",
+			Description: "optionally, the format to print; dotenv, json, shell or powershell, defaults to dotenv",
+		},
+		{
+			Name:        "filter",
+			Description: "optionally, a comma separated list of patterns, only secrets with matching names are printed, i.e. APP_*",
+		},
+		{
+			Name:        "force",
+			Description: "determines whether secrets restricted by a policy can be printed, which may still need to be confirmed",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
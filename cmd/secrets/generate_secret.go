@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/reecerussell/passport"
+)
+
+var generateSecretCommand = &passport.Command{
+	Name:        "generate",
+	Description: "used to generate a random secret, which isn't shown unless --print is given, i.e. passport secrets generate <name> --kind words",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("generate: no secret name specified")
+		}
+
+		opts := passport.GenerateOptions{
+			Kind:      cmd.Args.String("kind"),
+			Charset:   cmd.Args.String("charset"),
+			Separator: cmd.Args.String("separator"),
+		}
+
+		if v := cmd.Args.String("length"); v != "" {
+			length, err := strconv.Atoi(v)
+			if err != nil {
+				return passport.ErrGenerateLengthInvalid
+			}
+
+			opts.Length = length
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		// The name is checked first, as generating a key can be slow.
+		err = cnf.CheckNewSecret(cmd.Params[0])
+		if err != nil {
+			return err
+		}
+
+		s, err := passport.GenerateSecret(opts)
+		if err != nil {
+			return err
+		}
+
+		err = cnf.Update(func(c *passport.Config) error {
+			return c.AddSecret(cmd.Params[0], s.Value, !cmd.Args.Bool("plain-text"), ctx.Crypto)
+		})
+		if err != nil {
+			return err
+		}
+
+		if cmd.Args.Bool("print") {
+			fmt.Println(s.Value)
+		} else {
+			fmt.Println("Successfully generated secret!")
+		}
+
+		if s.PublicKey != "" {
+			fmt.Print(s.PublicKey)
+		}
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "kind",
+			Description: "optionally, the kind of secret to generate; chars, words, hex, base64, uuid, rsa or ed25519, defaults to chars",
+		},
+		{
+			Name:        "length",
+			Description: "optionally, the number of characters, words, random bytes for hex and base64, or bits for rsa keys",
+		},
+		{
+			Name:        "charset",
+			Description: "optionally, the characters to use; alnum, alpha, lower, upper, numeric, symbols, or the characters themselves, defaults to alnum",
+		},
+		{
+			Name:        "separator",
+			Description: "optionally, the separator used to join words, defaults to -",
+		},
+		{
+			Name:        "plain-text",
+			Description: "determines whether the value should be stored in plain text",
+			IsFlag:      true,
+		},
+		{
+			Name:        "print",
+			Description: "determines whether the generated value should be printed to stdout",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
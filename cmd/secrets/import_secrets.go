@@ -0,0 +1,138 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/reecerussell/passport"
+)
+
+var importSecretsCommand = &passport.Command{
+	Name:        "import",
+	Description: "used to import secrets from a dotenv, JSON or YAML file, i.e. passport secrets import .env",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("import: no file specified")
+		}
+
+		path := cmd.Params[0]
+		format := cmd.Args.String("format")
+		if format == "" {
+			format = importFormat(path)
+		}
+
+		data, err := ctx.Fs.Read(path)
+		if err != nil {
+			return err
+		}
+
+		values, err := passport.ParseSecrets(data, format)
+		if err != nil {
+			return err
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		opts := &passport.ImportOptions{
+			Prefix:    cmd.Args.String("prefix"),
+			Overwrite: cmd.Args.Bool("overwrite"),
+			DryRun:    cmd.Args.Bool("dry-run"),
+			Encrypt:   !cmd.Args.Bool("plain-text"),
+		}
+
+		var result *passport.ImportResult
+		update := func(c *passport.Config) error {
+			var err error
+			result, err = c.ImportSecrets(values, opts, ctx.Crypto)
+			return err
+		}
+
+		if opts.DryRun {
+			err = update(cnf)
+		} else {
+			err = cnf.Update(update)
+		}
+
+		if result != nil {
+			printImportResult(result)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if opts.DryRun {
+			fmt.Println("Dry run, no secrets were imported.")
+			return nil
+		}
+
+		fmt.Printf("Successfully imported %d secrets!\n", len(result.Added)+len(result.Updated))
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "format",
+			Description: "optionally, the format of the file; dotenv, json or yaml, defaults to the file's extension, otherwise dotenv",
+		},
+		{
+			Name:        "prefix",
+			Description: "optionally, a prefix added to the name of each secret",
+		},
+		{
+			Name:        "overwrite",
+			Description: "determines whether existing secrets should be replaced",
+			IsFlag:      true,
+		},
+		{
+			Name:        "dry-run",
+			Description: "determines whether the changes should only be listed, without importing any secrets",
+			IsFlag:      true,
+		},
+		{
+			Name:        "plain-text",
+			Description: "determines whether the values should be stored in plain text",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
+
+// importFormat returns the format of the file at path, based on its extension.
+func importFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return passport.FormatJSON
+	case ".yaml", ".yml":
+		return passport.FormatYAML
+	default:
+		return passport.FormatDotenv
+	}
+}
+
+func printImportResult(result *passport.ImportResult) {
+	for _, name := range result.Added {
+		fmt.Printf("+ %s\n", name)
+	}
+
+	for _, name := range result.Updated {
+		fmt.Printf("~ %s (overwritten)\n", name)
+	}
+
+	for _, name := range result.Conflicts {
+		fmt.Printf("! %s (already exists)\n", name)
+	}
+
+	for _, name := range result.Skipped {
+		fmt.Printf("- %s (skipped, empty value)\n", name)
+	}
+}
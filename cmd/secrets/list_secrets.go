@@ -1,7 +1,10 @@
 package secrets
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"time"
 
 	"github.com/reecerussell/passport"
 )
@@ -10,17 +13,83 @@ var listSecretsCommand = &passport.Command{
 	Name:        "ls",
 	Description: "used to list all secrets",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
 
+		workspace, global := cmd.Args.Bool("workspace"), cmd.Args.Bool("global")
+		if workspace && global {
+			return errors.New("secrets: --workspace and --global cannot be used together")
+		}
+
+		var secrets []*passport.Secret
+		var scopes []string
+		if !global {
+			wd, _ := os.Getwd()
+			w, err := cnf.GetWorkspace(wd)
+			if err == nil {
+				for _, s := range w.Secrets {
+					secrets = append(secrets, s)
+					scopes = append(scopes, "workspace")
+				}
+			} else if workspace || !errors.Is(err, passport.ErrWorkspaceNotFound) {
+				return err
+			}
+		}
+
+		if !workspace {
+			list, err := cnf.ListSecrets()
+			if err != nil {
+				return err
+			}
+
+			for _, s := range list {
+				secrets = append(secrets, s)
+				scopes = append(scopes, "global")
+			}
+		}
+
+		var within time.Duration
+		expiring := cmd.Args.String("expiring")
+		if expiring != "" {
+			within, err = passport.ParseDuration(expiring)
+			if err != nil {
+				return err
+			}
+		}
+
 		fmt.Println("Secrets:")
 
-		for _, s := range cnf.Secrets {
-			fmt.Printf("> %s\n", s.Name)
+		for i, s := range secrets {
+			if expiring != "" && !s.IsExpiring(within) {
+				continue
+			}
+
+			if expiry, ok := s.Expiry(); ok {
+				fmt.Printf("> %s (%s, expires %s)\n", s.Name, scopes[i], expiry.Local().Format("2006-01-02"))
+				continue
+			}
+
+			fmt.Printf("> %s (%s)\n", s.Name, scopes[i])
 		}
 
 		return nil
 	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "expiring",
+			Description: "optionally, only lists secrets which have expired, or expire within the given duration, i.e. 14d",
+		},
+		{
+			Name:        "workspace",
+			Description: "determines whether to only list the current workspace's secrets",
+			IsFlag:      true,
+		},
+		{
+			Name:        "global",
+			Description: "determines whether to only list the global secrets",
+			IsFlag:      true,
+		},
+	},
 }
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var receiveSecretsCommand = &passport.Command{
+	Name:        "receive",
+	Description: "used to add the secrets from a bundle shared with you, read from a file, or stdin, i.e. passport secrets receive bundle.age",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		var data []byte
+		var err error
+		if len(cmd.Params) > 0 {
+			data, err = ctx.Fs.Read(cmd.Params[0])
+		} else {
+			data, err = ioutil.ReadAll(os.Stdin)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		id, err := ctx.LoadIdentity()
+		if err != nil {
+			return err
+		}
+
+		b, err := id.OpenBundle(data)
+		if err != nil {
+			return err
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		err = cnf.Update(func(c *passport.Config) error {
+			return c.ReceiveSecrets(b, !cmd.Args.Bool("plain-text"), ctx.Crypto)
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Received from %s:\n", b.From)
+
+		for _, s := range b.Secrets {
+			fmt.Printf("> %s\n", s.Name)
+		}
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "plain-text",
+			Description: "determines whether the values should be stored in plain text",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
@@ -8,23 +8,26 @@ var removeSecretCommand = &passport.Command{
 	Name:        "rm",
 	Description: "used to remove a secret",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
 
-		name := cmd.Args.String("name")
-		err = cnf.RemoveSecret(name)
+		err = useScope(cnf, cmd.Args)
 		if err != nil {
 			return err
 		}
 
-		return cnf.Save()
+		name := cmd.Args.String("name")
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.RemoveSecret(name)
+		})
 	},
-	Args: passport.CommandArgs{
+	Args: append(passport.CommandArgs{
 		{
 			Name:        "name",
 			Description: "the name of the secret to remove",
 		},
-	},
+	}, scopeArgs()...),
 }
@@ -0,0 +1,51 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/reecerussell/passport"
+)
+
+var rollbackSecretCommand = &passport.Command{
+	Name:        "rollback",
+	Description: "used to set a secret back to a previous version, i.e. passport secrets rollback <name> --version 2",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("rollback: no secret name specified")
+		}
+
+		version, err := strconv.Atoi(cmd.Args.String("version"))
+		if err != nil {
+			return errors.New("rollback: version must be a number")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		err = cnf.Update(func(c *passport.Config) error {
+			return c.RollbackSecret(cmd.Params[0], version)
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Successfully rolled back to version %d!\n", version)
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "version",
+			Description: "the version of the secret to roll back to",
+		},
+	}, scopeArgs()...),
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/reecerussell/passport"
+)
+
+var secretExpiryCommand = &passport.Command{
+	Name:        "expiry",
+	Description: "used to set when a secret expires, or how often it should be rotated, i.e. passport secrets expiry <name> --rotate-every 90d",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("expiry: no secret name specified")
+		}
+
+		var expiresAt time.Time
+		if v := cmd.Args.String("expires-at"); v != "" {
+			t, err := parseTime(v)
+			if err != nil {
+				return err
+			}
+
+			expiresAt = t
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.SetSecretExpiry(cmd.Params[0], expiresAt, cmd.Args.String("rotate-every"))
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "expires-at",
+			Description: "optionally, the date the secret expires, i.e. 2021-12-31, otherwise the secret has no expiry date",
+		},
+		{
+			Name:        "rotate-every",
+			Description: "optionally, how often the secret should be changed, i.e. 90d, otherwise the secret is not rotated",
+		},
+	}, scopeArgs()...),
+}
+
+// parseTime parses a date, i.e. 2021-12-31, or an RFC 3339 timestamp.
+func parseTime(v string) (time.Time, error) {
+	t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+	if err == nil {
+		return t, nil
+	}
+
+	t, err = time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expiry: %s is not a valid date, i.e. 2021-12-31", v)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var secretHistoryCommand = &passport.Command{
+	Name:        "history",
+	Description: "used to list the versions of a secret, i.e. passport secrets history <name>",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("history: no secret name specified")
+		}
+
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		s, err := cnf.GetSecret(cmd.Params[0])
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Versions of %s:\n", s.Name)
+
+		versions := s.Versions()
+		for i := len(versions) - 1; i >= 0; i-- {
+			v := versions[i]
+			created := "unknown"
+			if !v.CreatedAt.IsZero() {
+				created = v.CreatedAt.Local().Format("2006-01-02 15:04:05")
+			}
+
+			current := ""
+			if i == len(versions)-1 {
+				current = " (current)"
+			}
+
+			fmt.Printf("> %d: %s%s\n", v.Version, created, current)
+		}
+
+		return nil
+	},
+	Args: scopeArgs(),
+}
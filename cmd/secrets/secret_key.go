@@ -0,0 +1,22 @@
+package secrets
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var secretKeyCommand = &passport.Command{
+	Name:        "key",
+	Description: "used to print your public key, which others use to share secrets with you",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		id, err := ctx.LoadIdentity()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(id.PublicKey)
+
+		return nil
+	},
+}
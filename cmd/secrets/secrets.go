@@ -1,7 +1,11 @@
 package secrets
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/reecerussell/passport"
 )
@@ -11,7 +15,7 @@ var Command = &passport.Command{
 	Name:        "secrets",
 	Description: "provides commands used to manage and view secrets",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
@@ -22,26 +26,151 @@ var Command = &passport.Command{
 			return nil
 		}
 
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
 		s, err := cnf.GetSecret(name)
 		if err != nil {
 			return err
 		}
 
+		err = cnf.CheckSecretRead(s, cmd.Args.Bool("force"))
+		if err != nil {
+			return err
+		}
+
+		v, err := s.GetValue(ctx.Crypto)
+		if err != nil {
+			return err
+		}
+
 		fmt.Printf("Name: %s\n", s.Name)
-		fmt.Printf("Value: %s\n", s.GetValue(ctx.Crypto))
+		if s.Source != "" {
+			fmt.Printf("Source: %s\n", s.Source)
+		}
+
+		fmt.Printf("Value: %s\n", v)
 		fmt.Printf("Secure: %v\n", s.Secure)
+		if len(s.Allow) > 0 {
+			allowed := make([]string, len(s.Allow))
+			for i, a := range s.Allow {
+				allowed[i] = a.String()
+			}
+
+			fmt.Printf("Allowed: %s\n", strings.Join(allowed, ", "))
+		}
+
+		if s.Confirm {
+			fmt.Printf("Confirm: %v\n", s.Confirm)
+		}
 
 		return nil
 	},
-	Args: passport.CommandArgs{
+	Args: append(passport.CommandArgs{
 		{
 			Name:        "name",
 			Description: "optionally, a name can be passed in to get a single secret",
 		},
-	},
+		{
+			Name:        "force",
+			Description: "determines whether a secret restricted by a policy can be shown, which may still need to be confirmed",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
 	Cmds: passport.CommandSet{
 		listSecretsCommand,
 		addSecretCommand,
 		removeSecretCommand,
+		setSecretCommand,
+		secretHistoryCommand,
+		rollbackSecretCommand,
+		secretExpiryCommand,
+		generateSecretCommand,
+		importSecretsCommand,
+		exportSecretsCommand,
+		shareSecretsCommand,
+		receiveSecretsCommand,
+		secretKeyCommand,
+		allowSecretCommand,
+		denySecretCommand,
+		confirmSecretCommand,
 	},
 }
+
+// scopeArgs returns the arguments used to select the scope of secrets.
+func scopeArgs() passport.CommandArgs {
+	return passport.CommandArgs{
+		{
+			Name:        "workspace",
+			Description: "determines whether to use the current workspace's secrets, rather than global ones",
+			IsFlag:      true,
+		},
+		{
+			Name:        "global",
+			Description: "determines whether to use the global secrets, which is the default",
+			IsFlag:      true,
+		},
+	}
+}
+
+// useScope selects the secrets of the current workspace, if given by args.
+// When reading secrets, the global secrets are used as a fallback.
+func useScope(cnf *passport.Config, args passport.CommandArgs) error {
+	if !args.Bool("workspace") {
+		return nil
+	}
+
+	if args.Bool("global") {
+		return errors.New("secrets: --workspace and --global cannot be used together")
+	}
+
+	w, err := currentWorkspace(cnf)
+	if err != nil {
+		return err
+	}
+
+	cnf.UseWorkspaceSecrets(w.Path)
+
+	return nil
+}
+
+// currentWorkspace returns the workspace of the working directory.
+func currentWorkspace(cnf *passport.Config) (*passport.Workspace, error) {
+	wd, _ := os.Getwd()
+
+	return cnf.GetWorkspace(wd)
+}
+
+// valueArgs returns the arguments used to give the value of a secret.
+func valueArgs() passport.CommandArgs {
+	return passport.CommandArgs{
+		{
+			Name:        "value",
+			Description: "optionally, the value of the secret, otherwise it's read from a prompt, or stdin",
+		},
+		{
+			Name:        "base64",
+			Description: "determines whether the value should be base64 encoded, allowing binary values",
+			IsFlag:      true,
+		},
+	}
+}
+
+// secretValue returns the value given by args. If a value isn't given, it
+// is read from a prompt, or stdin when piped, so it isn't kept in the
+// shell's history.
+func secretValue(args passport.CommandArgs) (string, error) {
+	encode := args.Bool("base64")
+	value := args.String("value")
+	if value == "" {
+		return passport.ReadSecretValue(os.Stdin, os.Stderr, encode)
+	}
+
+	if encode {
+		return base64.StdEncoding.EncodeToString([]byte(value)), nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,43 @@
+package secrets
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+var setSecretCommand = &passport.Command{
+	Name:        "set",
+	Description: "used to set the value of a secret, keeping its previous value in its history",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		name := cmd.Args.String("name")
+		plainText := cmd.Args.Bool("plain-text")
+		value, err := secretValue(cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			return c.SetSecret(name, value, !plainText, ctx.Crypto)
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the secret",
+		},
+		{
+			Name:        "plain-text",
+			Description: "determines whether the value should be stored in plain text",
+			IsFlag:      true,
+		},
+	}, append(valueArgs(), scopeArgs()...)...),
+}
@@ -0,0 +1,54 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/reecerussell/passport"
+)
+
+var shareSecretsCommand = &passport.Command{
+	Name:        "share",
+	Description: "used to print a bundle of secrets, encrypted to another user's public key, i.e. passport secrets share <name>... --to age1...",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		err = useScope(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		id, err := ctx.LoadIdentity()
+		if err != nil {
+			return err
+		}
+
+		var to []string
+		if v := cmd.Args.String("to"); v != "" {
+			to = strings.Split(v, ",")
+		}
+
+		bundle, err := cnf.ShareSecrets(cmd.Params, to, id, cmd.Args.Bool("force"), ctx.Crypto)
+		if err != nil {
+			return err
+		}
+
+		fmt.Print(string(bundle))
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "to",
+			Description: "a comma separated list of the public keys to share the secrets with, shown by passport secrets key",
+		},
+		{
+			Name:        "force",
+			Description: "determines whether secrets restricted by a policy can be shared, which may still need to be confirmed",
+			IsFlag:      true,
+		},
+	}, scopeArgs()...),
+}
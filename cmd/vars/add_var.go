@@ -0,0 +1,35 @@
+package vars
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+var addVarCommand = &passport.Command{
+	Name:        "add",
+	Description: "used to add a variable",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			vs, err := varSet(c, cmd.Args)
+			if err != nil {
+				return err
+			}
+
+			return vs.Add(cmd.Args.String("name"), cmd.Args.String("value"))
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the variable",
+		},
+		{
+			Name:        "value",
+			Description: "the value of the variable",
+		},
+	}, scopeArgs()...),
+}
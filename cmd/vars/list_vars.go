@@ -0,0 +1,32 @@
+package vars
+
+import (
+	"fmt"
+
+	"github.com/reecerussell/passport"
+)
+
+var listVarsCommand = &passport.Command{
+	Name:        "ls",
+	Description: "used to list all variables",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		vs, err := varSet(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Vars:")
+
+		for _, name := range vs.Names() {
+			fmt.Printf("> %s=%s\n", name, (*vs)[name])
+		}
+
+		return nil
+	},
+	Args: scopeArgs(),
+}
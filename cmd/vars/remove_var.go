@@ -0,0 +1,31 @@
+package vars
+
+import (
+	"github.com/reecerussell/passport"
+)
+
+var removeVarCommand = &passport.Command{
+	Name:        "rm",
+	Description: "used to remove a variable",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		return cnf.Update(func(c *passport.Config) error {
+			vs, err := varSet(c, cmd.Args)
+			if err != nil {
+				return err
+			}
+
+			return vs.Remove(cmd.Args.String("name"))
+		})
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the variable to remove",
+		},
+	}, scopeArgs()...),
+}
@@ -0,0 +1,93 @@
+package vars
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+// Command is the main entrypoint command for operations around variables.
+var Command = &passport.Command{
+	Name:        "vars",
+	Description: "provides commands used to manage and view plain-text variables",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		name := cmd.Args.String("name")
+		if name == "" {
+			cmd.Help()
+			return nil
+		}
+
+		vs, err := varSet(cnf, cmd.Args)
+		if err != nil {
+			return err
+		}
+
+		v, err := vs.Get(name)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Name: %s\n", name)
+		fmt.Printf("Value: %s\n", v)
+
+		return nil
+	},
+	Args: append(passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "optionally, a name can be passed in to get a single variable",
+		},
+	}, scopeArgs()...),
+	Cmds: passport.CommandSet{
+		listVarsCommand,
+		addVarCommand,
+		removeVarCommand,
+	},
+}
+
+// scopeArgs returns the arguments used to select the scope of a variable.
+func scopeArgs() passport.CommandArgs {
+	return passport.CommandArgs{
+		{
+			Name:        "workspace",
+			Description: "determines whether to use the current workspace's variables, rather than global ones",
+			IsFlag:      true,
+		},
+		{
+			Name:        "script",
+			Description: "optionally, the name of a script in the current workspace to use the variables of",
+		},
+	}
+}
+
+// varSet returns a pointer to the variables in the scope selected by args;
+// either the global config, the current workspace, or one of its scripts.
+func varSet(cnf *passport.Config, args passport.CommandArgs) (*passport.VarSet, error) {
+	script := args.String("script")
+	if !args.Bool("workspace") && script == "" {
+		return &cnf.Vars, nil
+	}
+
+	wd, _ := os.Getwd()
+	w, err := cnf.GetWorkspace(wd)
+	if err != nil {
+		return nil, err
+	}
+
+	if script == "" {
+		return &w.Vars, nil
+	}
+
+	s, err := w.GetScript(script)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s.Vars, nil
+}
@@ -0,0 +1,27 @@
+package vault
+
+import (
+	"errors"
+
+	"github.com/reecerussell/passport"
+)
+
+var addMemberCommand = &passport.Command{
+	Name:        "add-member",
+	Description: "used to give another user access to the team vault, i.e. passport vault add-member <public key>",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("add-member: no public key specified")
+		}
+
+		return updateVault(ctx, func(v *passport.TeamVault, id *passport.Identity) error {
+			return v.AddMember(id, cmd.Params[0], cmd.Args.String("name"))
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "optionally, the name of the member, shown in the list of members",
+		},
+	},
+}
@@ -0,0 +1,35 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var initVaultCommand = &passport.Command{
+	Name:        "init",
+	Description: "used to create a team vault in the current directory, where you are the first member",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		id, err := ctx.LoadIdentity()
+		if err != nil {
+			return err
+		}
+
+		wd, _ := os.Getwd()
+		_, err = passport.NewTeamVault(wd, ctx.Fs, id, cmd.Args.String("name"))
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Created %s, which can be committed to share it with your team.\n", passport.TeamVaultFilename)
+
+		return nil
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "optionally, your name, shown in the list of members",
+		},
+	},
+}
@@ -0,0 +1,33 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var listMembersCommand = &passport.Command{
+	Name:        "members",
+	Description: "used to list the members of the team vault",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		wd, _ := os.Getwd()
+		v, err := passport.LoadTeamVault(wd, ctx.Fs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Members:")
+
+		for _, m := range v.Members {
+			if m.Name != "" {
+				fmt.Printf("> %s (%s)\n", m.Name, m.PublicKey)
+				continue
+			}
+
+			fmt.Printf("> %s\n", m.PublicKey)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,28 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var listSecretsCommand = &passport.Command{
+	Name:        "ls",
+	Description: "used to list the secrets in the team vault",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		wd, _ := os.Getwd()
+		v, err := passport.LoadTeamVault(wd, ctx.Fs)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Secrets:")
+
+		for _, s := range v.Secrets {
+			fmt.Printf("> %s\n", s.Name)
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,21 @@
+package vault
+
+import (
+	"errors"
+
+	"github.com/reecerussell/passport"
+)
+
+var removeMemberCommand = &passport.Command{
+	Name:        "remove-member",
+	Description: "used to remove a member, by public key or name, from the team vault, which encrypts its secrets with a new key",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		if len(cmd.Params) < 1 {
+			return errors.New("remove-member: no member specified")
+		}
+
+		return updateVault(ctx, func(v *passport.TeamVault, id *passport.Identity) error {
+			return v.RemoveMember(id, cmd.Params[0])
+		})
+	},
+}
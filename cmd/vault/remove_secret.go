@@ -0,0 +1,25 @@
+package vault
+
+import (
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var removeSecretCommand = &passport.Command{
+	Name:        "rm",
+	Description: "used to remove a secret from the team vault",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		wd, _ := os.Getwd()
+
+		return passport.UpdateTeamVault(wd, ctx.Fs, func(v *passport.TeamVault) error {
+			return v.RemoveSecret(cmd.Args.String("name"))
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the secret to remove",
+		},
+	},
+}
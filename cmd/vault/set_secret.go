@@ -0,0 +1,42 @@
+package vault
+
+import (
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+var setSecretCommand = &passport.Command{
+	Name:        "set",
+	Description: "used to add a secret to the team vault, or change its value",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		// The vault is only locked once the value has been read.
+		wd, _ := os.Getwd()
+		_, err := passport.LoadTeamVault(wd, ctx.Fs)
+		if err != nil {
+			return err
+		}
+
+		value := cmd.Args.String("value")
+		if value == "" {
+			value, err = passport.ReadSecretValue(os.Stdin, os.Stderr, false)
+			if err != nil {
+				return err
+			}
+		}
+
+		return updateVault(ctx, func(v *passport.TeamVault, id *passport.Identity) error {
+			return v.SetSecret(id, cmd.Args.String("name"), value)
+		})
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "name",
+			Description: "the name of the secret",
+		},
+		{
+			Name:        "value",
+			Description: "optionally, the value of the secret, otherwise it's read from a prompt, or stdin",
+		},
+	},
+}
@@ -0,0 +1,41 @@
+package vault
+
+import (
+	"os"
+
+	"github.com/reecerussell/passport"
+)
+
+// Command is the main entrypoint command for operations around team vaults.
+var Command = &passport.Command{
+	Name:        "vault",
+	Description: "provides commands used to manage the team vault in the current directory, which can be committed to a repository",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cmd.Help()
+		return nil
+	},
+	Cmds: passport.CommandSet{
+		initVaultCommand,
+		listMembersCommand,
+		addMemberCommand,
+		removeMemberCommand,
+		listSecretsCommand,
+		setSecretCommand,
+		removeSecretCommand,
+	},
+}
+
+// updateVault loads the user's identity, then calls fn with it, and the
+// team vault in the working directory, which is locked until it's saved.
+func updateVault(ctx *passport.CommandContext, fn func(v *passport.TeamVault, id *passport.Identity) error) error {
+	id, err := ctx.LoadIdentity()
+	if err != nil {
+		return err
+	}
+
+	wd, _ := os.Getwd()
+
+	return passport.UpdateTeamVault(wd, ctx.Fs, func(v *passport.TeamVault) error {
+		return fn(v, id)
+	})
+}
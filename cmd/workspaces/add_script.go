@@ -11,27 +11,24 @@ var addScriptsCommand = &passport.Command{
 	Name:        "add",
 	Description: "used add a new script to a workspace",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
 
 		wd, _ := os.Getwd()
-		w, err := cnf.GetWorkspace(wd)
-		if err != nil {
-			cnf.AddWorkspace(wd, wd)
-			w, _ = cnf.GetWorkspace(wd)
-		}
-
 		name := cmd.Args.String("name")
 		command := cmd.Args.String("command")
 
-		err = w.AddScript(name, command)
-		if err != nil {
-			return err
-		}
+		err = cnf.Update(func(c *passport.Config) error {
+			w, err := c.GetWorkspace(wd)
+			if err != nil {
+				c.AddWorkspace(wd, wd)
+				w, _ = c.GetWorkspace(wd)
+			}
 
-		err = cnf.Save()
+			return w.AddScript(name, command)
+		})
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,68 @@
+package workspaces
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/reecerussell/passport"
+)
+
+// EnvCommand is a command used to print the variables, and profile's
+// secrets, of a workspace, as statements which can be evaluated by a shell.
+var EnvCommand = &passport.Command{
+	Name:        "env",
+	Description: "used to print the environment of a workspace, i.e. eval \"$(passport env)\"",
+	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
+		cnf, err := ctx.LoadConfig()
+		if err != nil {
+			return err
+		}
+
+		wd, _ := os.Getwd()
+		w, err := cnf.GetWorkspace(wd)
+		if err != nil {
+			return err
+		}
+
+		profile := cmd.Args.String("profile")
+		if profile == "" {
+			profile = os.Getenv(passport.ProfileEnvVar)
+		}
+
+		cnf.UseProfile(profile)
+
+		if _, err := w.TeamVault(); err == nil {
+			id, err := ctx.LoadIdentity()
+			if err != nil {
+				return err
+			}
+
+			cnf.UseIdentity(id)
+		}
+
+		values, err := w.Env(ctx.Crypto)
+		if err != nil {
+			return err
+		}
+
+		format := cmd.Args.String("format")
+		if format == "" {
+			format = passport.FormatShell
+			if runtime.GOOS == "windows" {
+				format = passport.FormatPowerShell
+			}
+		}
+
+		return passport.FormatSecrets(os.Stdout, values, format)
+	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "profile",
+			Description: "the profile used to resolve variables and secrets, defaults to $PASSPORT_PROFILE",
+		},
+		{
+			Name:        "format",
+			Description: "optionally, the format to print; shell, powershell, dotenv or json, defaults to powershell on Windows, otherwise shell",
+		},
+	},
+}
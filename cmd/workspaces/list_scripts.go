@@ -11,7 +11,7 @@ var listScriptsCommand = &passport.Command{
 	Name:        "ls",
 	Description: "used to list scripts in a workspace",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
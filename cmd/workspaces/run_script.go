@@ -13,7 +13,7 @@ var RunScriptCommand = &passport.Command{
 	Name:        "run",
 	Description: "used execute a script in a workspace",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
@@ -24,17 +24,33 @@ var RunScriptCommand = &passport.Command{
 			return err
 		}
 
-		if len(os.Args) < 3 {
+		if len(cmd.Params) < 1 {
 			return errors.New("run: no script name specified")
 		}
 
-		name := os.Args[2]
+		name := cmd.Params[0]
 		s, err := w.GetScript(name)
 		if err != nil {
 			return err
 		}
 
-		exitCode, err := s.Run(ctx.Crypto)
+		profile := cmd.Args.String("profile")
+		if profile == "" {
+			profile = os.Getenv(passport.ProfileEnvVar)
+		}
+
+		cnf.UseProfile(profile)
+
+		if _, err := w.TeamVault(); err == nil {
+			id, err := ctx.LoadIdentity()
+			if err != nil {
+				return err
+			}
+
+			cnf.UseIdentity(id)
+		}
+
+		exitCode, err := s.Run(ctx.Crypto, cmd.Params[1:]...)
 		if err != nil {
 			return err
 		}
@@ -43,4 +59,10 @@ var RunScriptCommand = &passport.Command{
 
 		return nil
 	},
+	Args: passport.CommandArgs{
+		{
+			Name:        "profile",
+			Description: "the profile used to resolve variables and secrets, defaults to $PASSPORT_PROFILE",
+		},
+	},
 }
@@ -12,7 +12,7 @@ var ScriptsCommand = &passport.Command{
 	Name:        "scripts",
 	Description: "provides commands used to manage workspace scripts",
 	Execute: func(cmd *passport.Command, ctx *passport.CommandContext) error {
-		cnf, err := passport.LoadConfig(ctx.ConfigDir, ctx.Fs)
+		cnf, err := ctx.LoadConfig()
 		if err != nil {
 			return err
 		}
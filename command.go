@@ -1,6 +1,9 @@
 package passport
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // ExecuteFunc is a function type used to define how a Command is executed.
 type ExecuteFunc func(cmd *Command, ctx *CommandContext) error
@@ -14,22 +17,39 @@ type Command struct {
 	Execute     ExecuteFunc
 	Args        CommandArgs
 	Cmds        CommandSet
+
+	// Params holds the positional arguments given to the command,
+	// which were not consumed by Args, in the order they were given.
+	Params []string
 }
 
-// ParseArgs deserialises args into the command's arguments.
+// ParseArgs deserialises args into the command's arguments. Any
+// positional arguments are added to the command's Params. Unknown flags
+// given after the first positional argument are kept as Params too, so
+// they can be passed on, i.e. to a script, as can every argument after "--".
 func (cmd *Command) ParseArgs(args []string) {
+	cmd.Params = nil
+
 	for i := 0; i < len(args); i++ {
 		arg := args[i]
-		if arg[0] != '-' {
+		if arg == "--" {
+			cmd.Params = append(cmd.Params, args[i+1:]...)
+			return
+		}
+
+		if arg == "" || arg[0] != '-' {
+			cmd.Params = append(cmd.Params, arg)
 			continue
 		}
 
+		known := false
 		for _, cmdArg := range cmd.Args {
 			if "--"+cmdArg.Name != arg {
 				continue
 			}
 
-			if len(args) > i+1 && args[i+1][0] != '-' && !cmdArg.IsFlag {
+			known = true
+			if len(args) > i+1 && !strings.HasPrefix(args[i+1], "-") && !cmdArg.IsFlag {
 				cmdArg.Value = args[i+1]
 				i++
 			}
@@ -38,6 +58,10 @@ func (cmd *Command) ParseArgs(args []string) {
 				cmdArg.Value = "true"
 			}
 		}
+
+		if !known && len(cmd.Params) > 0 {
+			cmd.Params = append(cmd.Params, arg)
+		}
 	}
 }
 
@@ -86,17 +110,48 @@ type CommandArg struct {
 // CommandContext is a struct provided to each command's execute
 // function, providing common values.
 type CommandContext struct {
+	// ConfigDir is the directory of the selected store.
 	ConfigDir string
 	Crypto    CryptoProvider
 	Fs        Filesys
+
+	// Stores are the config stores, ordered by precedence, and Store
+	// is the name of the selected store, which changes are saved to.
+	Stores []*Store
+	Store  string
+}
+
+// LoadConfig loads the config of the selected store, layered with
+// the other stores. If there are no stores, the config in ConfigDir
+// is loaded on its own.
+func (ctx *CommandContext) LoadConfig() (*Config, error) {
+	if len(ctx.Stores) == 0 {
+		return LoadConfig(ctx.ConfigDir, ctx.Fs, ctx.Crypto)
+	}
+
+	return LoadStores(ctx.Stores, ctx.Store, ctx.Fs, ctx.Crypto)
+}
+
+// LoadIdentity loads the user's identity, which is kept in the personal
+// store, generating one if needed. If there are no stores, the identity
+// in ConfigDir is loaded.
+func (ctx *CommandContext) LoadIdentity() (*Identity, error) {
+	dir := ctx.ConfigDir
+	for _, s := range ctx.Stores {
+		if s.Name == StorePersonal {
+			dir = s.Dir
+		}
+	}
+
+	return LoadIdentity(dir, ctx.Fs, ctx.Crypto)
 }
 
 // CommandSet is a wrapper around []*Command, which provides helper functions.
 type CommandSet []*Command
 
-// ParseCommand returns a command matching the given args. If a command
-// is found, the args are then parsed and the command is returned.
-// Otherwise, nil is returned.
+// ParseCommand returns a command, or nested sub-command, matching the
+// given args. If a command is found, the args are then parsed and the
+// command is returned. Otherwise, nil is returned.
 func (set CommandSet) ParseCommand(args []string) *Command {
 	if args == nil || len(args) < 1 {
 		return nil
@@ -109,10 +164,9 @@ func (set CommandSet) ParseCommand(args []string) *Command {
 			continue
 		}
 
-		if len(args) > 1 && args[1][0] != '-' {
+		if len(args) > 1 && !strings.HasPrefix(args[1], "-") {
 			c := cmd.Cmds.ParseCommand(args[1:])
 			if c != nil {
-				c.ParseArgs(args[2:])
 				return c
 			}
 		}
@@ -19,6 +19,17 @@ func TestCommandSet_ParseCommand(t *testing.T) {
 				{
 					Name: "add",
 				},
+				{
+					Name: "stats",
+					Cmds: []*Command{
+						{
+							Name: "mean",
+							Args: CommandArgs{
+								{Name: "x"},
+							},
+						},
+					},
+				},
 			},
 		},
 	}
@@ -34,6 +45,14 @@ func TestCommandSet_ParseCommand(t *testing.T) {
 		assert.Equal(t, set[1].Cmds[0], cmd)
 	})
 
+	t.Run("Get Nested Math Stats Mean Command", func(t *testing.T) {
+		args := []string{"math", "stats", "mean", "--x", "1", "2"}
+		cmd := set.ParseCommand(args)
+		assert.Equal(t, set[1].Cmds[1].Cmds[0], cmd)
+		assert.Equal(t, "1", cmd.Args.String("x"))
+		assert.Equal(t, []string{"2"}, cmd.Params)
+	})
+
 	t.Run("Given Invalid Args", func(t *testing.T) {
 		args := []string{"test", "command"}
 		cmd := set.ParseCommand(args)
@@ -107,6 +126,46 @@ func TestCommand_ParseArgs(t *testing.T) {
 	assert.Equal(t, "true", flagArg.Value)
 }
 
+func TestCommand_ParseArgs_Params(t *testing.T) {
+	nameArg := &CommandArg{Name: "name"}
+
+	cmd := &Command{
+		Args: []*CommandArg{
+			nameArg,
+		},
+	}
+
+	args := []string{"build", "--name", "reece", "", "deploy"}
+	cmd.ParseArgs(args)
+
+	assert.Equal(t, "reece", nameArg.Value)
+	assert.Equal(t, []string{"build", "", "deploy"}, cmd.Params)
+}
+
+func TestCommand_ParseArgs_UnknownFlags(t *testing.T) {
+	t.Run("Given Unknown Flag Before Params", func(t *testing.T) {
+		cmd := &Command{}
+		cmd.ParseArgs([]string{"-x", "build"})
+		assert.Equal(t, []string{"build"}, cmd.Params)
+	})
+
+	t.Run("Given Unknown Flags After Params", func(t *testing.T) {
+		nameArg := &CommandArg{Name: "name"}
+		cmd := &Command{Args: []*CommandArg{nameArg}}
+		cmd.ParseArgs([]string{"build", "-x", "--verbose", "--name", "reece", "y"})
+		assert.Equal(t, "reece", nameArg.Value)
+		assert.Equal(t, []string{"build", "-x", "--verbose", "y"}, cmd.Params)
+	})
+
+	t.Run("Given Double Dash", func(t *testing.T) {
+		nameArg := &CommandArg{Name: "name"}
+		cmd := &Command{Args: []*CommandArg{nameArg}}
+		cmd.ParseArgs([]string{"--", "--name", "reece", "--"})
+		assert.Equal(t, "", nameArg.Value)
+		assert.Equal(t, []string{"--name", "reece", "--"}, cmd.Params)
+	})
+}
+
 func TestCommand_Help(t *testing.T) {
 	cmd := &Command{
 		Name:        "TestCommand",
@@ -7,28 +7,90 @@ import (
 	"os"
 	"os/exec"
 	"path"
-	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/reecerussell/passport/template"
 )
 
-const configFilename = "config.yaml"
+const (
+	configFilename     = "config.yaml"
+	configLockFilename = "config.yaml.lock"
+)
 
 // Config is a struct which holds and represents the core configuration.
 type Config struct {
-	configDir string  `yaml:"-"`
-	fs        Filesys `yaml:"-"`
-
+	configDir string         `yaml:"-"`
+	fs        Filesys        `yaml:"-"`
+	cp        CryptoProvider `yaml:"-"`
+	profile   string         `yaml:"-"`
+	encrypted bool           `yaml:"-"`
+
+	// store is the name of the store the config was loaded from, and
+	// layers are the configs of every store, ordered by precedence.
+	store    string    `yaml:"-"`
+	layers   []*Config `yaml:"-"`
+	readOnly bool      `yaml:"-"`
+	snapshot []byte    `yaml:"-"`
+
+	secretStore SecretStore     `yaml:"-"`
+	vaultStore  SecretStore     `yaml:"-"`
+	warned      map[string]bool `yaml:"-"`
+	confirmed   map[string]bool `yaml:"-"`
+	identity    *Identity       `yaml:"-"`
+	secretScope string          `yaml:"-"`
+
+	Version    int          `yaml:"version"`
 	Secrets    []*Secret    `yaml:"secrets"`
 	Workspaces []*Workspace `yaml:"workspaces"`
+	Profiles   ProfileSet   `yaml:"profiles,omitempty"`
+	Vars       VarSet       `yaml:"vars,omitempty"`
+
+	// BackupRetention is the number of backups of the config file to keep.
+	// If zero, DefaultBackupRetention is used, and if negative, backups
+	// are disabled.
+	BackupRetention int `yaml:"backup_retention,omitempty"`
+
+	// Vault, if set, configures secrets to be kept in HashiCorp Vault,
+	// rather than the config file.
+	Vault *VaultConfig `yaml:"vault,omitempty"`
+
+	// ExpiryWarning is how long before a secret expires that warnings are
+	// printed, i.e. "14d". If FailOnExpired is set, scripts referencing an
+	// expired secret fail to run.
+	ExpiryWarning string `yaml:"expiry_warning,omitempty"`
+	FailOnExpired bool   `yaml:"fail_on_expired,omitempty"`
 }
 
-// Save writes the current config object to the config file.
+// Save writes the current config object to the config file. The
+// existing config file is backed up, before it is overwritten. If the
+// config is encrypted, the document is encrypted before it is written.
+// If the config of another store has been changed, ErrStoreReadOnly is
+// returned.
 func (c *Config) Save() error {
+	err := c.checkLayers()
+	if err != nil {
+		return err
+	}
+
 	filePath := path.Join(c.configDir, configFilename)
+	c.Version = ConfigVersion
 	bytes, _ := yaml.Marshal(c)
-	err := c.fs.Write(filePath, bytes)
+	bytes, err = c.encode(bytes)
+	if err != nil {
+		return err
+	}
+
+	err = c.backup()
+	if err != nil {
+		return err
+	}
+
+	err = c.fs.Write(filePath, bytes)
 	if err != nil {
 		return err
 	}
@@ -51,6 +113,7 @@ func EnsureConfigFile(configDir string, fs Filesys) error {
 	}
 
 	cnf := Config{
+		Version:    ConfigVersion,
 		Secrets:    make([]*Secret, 0),
 		Workspaces: make([]*Workspace, 0),
 	}
@@ -65,42 +128,167 @@ func EnsureConfigFile(configDir string, fs Filesys) error {
 }
 
 // LoadConfig loads a configuration file from configDir. An
-// error will be returned if one does not exist.
-func LoadConfig(configDir string, fs Filesys) (*Config, error) {
-	filePath := path.Join(configDir, configFilename)
-	bytes, err := fs.Read(filePath)
+// error will be returned if one does not exist, cannot be parsed,
+// or is invalid. If the file is encrypted, it is decrypted using cp.
+func LoadConfig(configDir string, fs Filesys, cp CryptoProvider) (*Config, error) {
+	c := &Config{
+		configDir: configDir,
+		fs:        fs,
+		cp:        cp,
+	}
+
+	err := c.load()
 	if err != nil {
 		return nil, err
 	}
 
-	var c Config
-	c.configDir = configDir
-	c.fs = fs
-	_ = yaml.Unmarshal(bytes, &c)
+	return c, nil
+}
+
+// load reads the config file, replacing any values held by c. If the
+// file cannot be parsed, an error containing the line number is returned.
+// Encrypted config files are decrypted, and config files with an older
+// version are migrated, then saved, unless they belong to another store.
+// The configs of other stores are then reloaded.
+func (c *Config) load() error {
+	filePath := path.Join(c.configDir, configFilename)
+	bytes, err := c.fs.Read(filePath)
+	if err != nil {
+		return err
+	}
+
+	bytes, encrypted, err := c.decode(filePath, bytes)
+	if err != nil {
+		return err
+	}
+
+	c.encrypted = encrypted
+	bytes, migrated, err := c.migrate(filePath, bytes)
+	if err != nil {
+		return err
+	}
+
+	*c = Config{
+		configDir: c.configDir,
+		fs:        c.fs,
+		cp:        c.cp,
+		profile:   c.profile,
+		encrypted: c.encrypted,
+		store:     c.store,
+		layers:    c.layers,
+		readOnly:  c.readOnly,
+
+		secretStore: c.secretStore,
+		warned:      c.warned,
+		confirmed:   c.confirmed,
+		identity:    c.identity,
+		secretScope: c.secretScope,
+	}
+
+	err = yaml.Unmarshal(bytes, c)
+	if err != nil {
+		return fmt.Errorf("config: failed to parse %s: %w", filePath, err)
+	}
+
+	err = c.Validate()
+	if err != nil {
+		return err
+	}
+
+	// The migrated document is written, rather than the config, so the
+	// user's file keeps its order and comments.
+	if migrated && !c.readOnly {
+		bytes, err = c.encode(bytes)
+		if err != nil {
+			return err
+		}
+
+		err = c.fs.Write(filePath, bytes)
+		if err != nil {
+			return err
+		}
+	}
 
-	return &c, nil
+	return c.loadLayers()
+}
+
+// Update safely modifies the config file, by holding a lock on it while
+// the config is reloaded, modified by fn and saved. This prevents changes
+// made by concurrent processes from being lost. Any unsaved changes to c
+// are discarded when it is reloaded. If fn returns an error, the config
+// file will not be saved, and the error is returned.
+func (c *Config) Update(fn func(c *Config) error) error {
+	unlock, err := c.fs.Lock(path.Join(c.configDir, configLockFilename))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	err = c.load()
+	if err != nil {
+		return err
+	}
+
+	err = fn(c)
+	if err != nil {
+		return err
+	}
+
+	return c.Save()
 }
 
 // Secret is a struct which represents a stored secret value.
 type Secret struct {
 	Name   string `yaml:"name"`
-	Value  string `yaml:"value"`
+	Value  string `yaml:"value,omitempty"`
 	Secure bool   `yaml:"secure"`
+
+	// Source, if set, is where the secret's value is read from, in place
+	// of Value, either a file, i.e. "file:~/token", or the output of a
+	// command, i.e. "exec:aws ecr get-login-password".
+	Source string `yaml:"source,omitempty"`
+	// Timeout is the time an exec source is given to run, i.e. "10s".
+	Timeout string `yaml:"timeout,omitempty"`
+
+	// Version is the version of the secret's value, which is incremented
+	// each time it's updated, where previous versions are kept in History.
+	Version   int              `yaml:"version,omitempty"`
+	UpdatedAt time.Time        `yaml:"updated_at,omitempty"`
+	History   []*SecretVersion `yaml:"history,omitempty"`
+
+	// ExpiresAt is, optionally, when the secret's value expires, and
+	// RotateEvery is how often it should be changed, i.e. "90d".
+	ExpiresAt   time.Time `yaml:"expires_at,omitempty"`
+	RotateEvery string    `yaml:"rotate_every,omitempty"`
+
+	// Allow, if set, limits which workspaces, and scripts, may use the
+	// secret, and Confirm makes its use be confirmed at a prompt first.
+	Allow   []*SecretAccess `yaml:"allow,omitempty"`
+	Confirm bool            `yaml:"confirm,omitempty"`
+
+	resolved      bool   `yaml:"-"`
+	resolvedValue string `yaml:"-"`
 }
 
 // GetValue returns the secret's value in plain text. If the is
-// encrypted, it will be decrypted before being returned.
-func (s *Secret) GetValue(cp CryptoProvider) string {
+// encrypted, it will be decrypted before being returned. If the
+// secret has a source, its value is read from the source.
+func (s *Secret) GetValue(cp CryptoProvider) (string, error) {
+	if s.Source != "" {
+		return s.readSource()
+	}
+
 	if !s.Secure {
-		return s.Value
+		return s.Value, nil
 	}
 
 	v, err := cp.DecryptString(s.Value)
 	if err != nil {
-		return ""
+		return "", err
 	}
 
-	return v
+	return v, nil
 }
 
 var (
@@ -110,23 +298,19 @@ var (
 	ErrSecretAlreadyExists = errors.New("secret: already exists")
 )
 
-// AddSecret saves a new secret in the configuration file, with the
+// AddSecret saves a new secret in the config's SecretStore, with the
 // given name and value. If the encrypt flag is true, the value
-// will be encrypted before added to the config.
+// will be encrypted before added to the store.
 func (c *Config) AddSecret(name, value string, encrypt bool, cp CryptoProvider) error {
-	if name == "" {
-		return ErrSecretNameEmpty
+	err := c.CheckNewSecret(name)
+	if err != nil {
+		return err
 	}
 
 	if value == "" {
 		return ErrSecretValueEmpty
 	}
 
-	_, err := c.GetSecret(name)
-	if err == nil {
-		return ErrSecretAlreadyExists
-	}
-
 	if encrypt {
 		secureString, err := cp.EncryptString(value)
 		if err != nil {
@@ -136,47 +320,132 @@ func (c *Config) AddSecret(name, value string, encrypt bool, cp CryptoProvider)
 		value = secureString
 	}
 
-	c.Secrets = append(c.Secrets, &Secret{
-		Name:   name,
-		Value:  value,
-		Secure: encrypt,
+	return c.SecretStore().Put(&Secret{
+		Name:      name,
+		Value:     value,
+		Secure:    encrypt,
+		Version:   1,
+		UpdatedAt: now().UTC().Truncate(time.Second),
 	})
+}
+
+// AddSecretSource saves a new secret in the config's SecretStore, with
+// the given name, which reads its value from source when used.
+func (c *Config) AddSecretSource(name, source, timeout string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	err := validateSecretSource(source)
+	if err != nil {
+		return err
+	}
+
+	secret := &Secret{
+		Name:      name,
+		Source:    source,
+		Timeout:   timeout,
+		Version:   1,
+		UpdatedAt: now().UTC().Truncate(time.Second),
+	}
+
+	_, err = secret.timeout()
+	if err != nil {
+		return err
+	}
+
+	err = c.CheckNewSecret(name)
+	if err != nil {
+		return err
+	}
+
+	return c.SecretStore().Put(secret)
+}
+
+// CheckNewSecret returns an error if a secret can't be added with the given
+// name, as it's empty, or the secret already exists in the config's
+// SecretStore. This allows a name to be checked before its value is read.
+func (c *Config) CheckNewSecret(name string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	_, err := c.SecretStore().Get(name)
+	if err == nil {
+		return ErrSecretAlreadyExists
+	}
+
+	if !errors.Is(err, ErrSecretNotFound) {
+		return err
+	}
 
 	return nil
 }
 
-// GetSecret returns a secret from the config, where the name is equal
-// to name. If the secret does not exist, ErrSecretNotFound will be returned.
+// GetSecret returns a secret, where the name is equal to name, from the
+// SecretStore of the first config store which defines it. If workspace
+// secrets are used, the workspace's secrets are checked first. If the
+// secret does not exist, ErrSecretNotFound will be returned.
 func (c *Config) GetSecret(name string) (*Secret, error) {
 	if name == "" {
 		return nil, ErrSecretNameEmpty
 	}
 
-	for _, secret := range c.Secrets {
-		if secret.Name == name {
+	if c.secretScope != "" {
+		secret, err := c.SecretStore().Get(name)
+		if !errors.Is(err, ErrSecretNotFound) {
+			return secret, err
+		}
+	}
+
+	for _, l := range c.stack() {
+		secret, err := l.globalSecretStore().Get(name)
+		if err == nil {
 			return secret, nil
 		}
+
+		if !errors.Is(err, ErrSecretNotFound) {
+			return nil, err
+		}
 	}
 
 	return nil, ErrSecretNotFound
 }
 
-// RemoveSecret removes the secret with the given name from the config.
-// If the secret does not exist, ErrSecretNotFound will be returned.
-func (c *Config) RemoveSecret(name string) error {
-	if name == "" {
-		return ErrSecretNameEmpty
-	}
+// ListSecrets returns the global secrets of every store, ordered by the
+// store's precedence, where a secret is taken from the first store which
+// defines it.
+func (c *Config) ListSecrets() ([]*Secret, error) {
+	seen := make(map[string]bool)
+	var secrets []*Secret
+	for _, l := range c.stack() {
+		list, err := l.globalSecretStore().List()
+		if err != nil {
+			return nil, err
+		}
 
-	for i := 0; i < len(c.Secrets); i++ {
-		if c.Secrets[i].Name == name {
-			c.Secrets = append(c.Secrets[:i], c.Secrets[i+1:]...)
+		for _, s := range list {
+			if seen[s.Name] {
+				continue
+			}
 
-			return nil
+			seen[s.Name] = true
+			secrets = append(secrets, s)
 		}
 	}
 
-	return ErrSecretNotFound
+	return secrets, nil
+}
+
+// RemoveSecret removes the secret with the given name from the config's
+// SecretStore. If the secret does not exist, ErrSecretNotFound will be
+// returned.
+func (c *Config) RemoveSecret(name string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	return c.SecretStore().Delete(name)
 }
 
 // Common workspace errors.
@@ -191,6 +460,8 @@ var (
 	ErrWorkspaceScriptNameExists   = errors.New("script: name already exists")
 	ErrWorkspaceScriptCommandEmpty = errors.New("script: command is empty")
 	ErrWorkspaceScriptNotFound     = errors.New("script: not found")
+
+	ErrWorkspaceScriptUnclosedQuote = errors.New("script: unclosed quote in command")
 )
 
 // Workspace is a struct which represents a workspace. A workspace
@@ -198,19 +469,32 @@ var (
 type Workspace struct {
 	// Config is a pointer to the parent Config object.
 	c *Config `yaml:"-"`
-
-	Name    string             `yaml:"name"`
-	Path    string             `yaml:"path"`
-	Scripts []*WorkspaceScript `yaml:"scripts"`
+	// vault is the team vault in the workspace's directory, once read.
+	vault *TeamVault `yaml:"-"`
+
+	Name     string             `yaml:"name"`
+	Path     string             `yaml:"path"`
+	Scripts  []*WorkspaceScript `yaml:"scripts"`
+	Profiles ProfileSet         `yaml:"profiles,omitempty"`
+	Vars     VarSet             `yaml:"vars,omitempty"`
+
+	// Secrets are only available to the workspace's scripts, taking
+	// precedence over global secrets with the same name.
+	Secrets []*Secret `yaml:"secrets,omitempty"`
 }
 
 // WorkspaceScript represents a script which can be run within a workspace.
 type WorkspaceScript struct {
 	// Config is used to provide the Run function with secrets.
 	c *Config `yaml:"-"`
+	// Workspace is used to provide the Run function with profiles.
+	w *Workspace `yaml:"-"`
+	// tmpl is the parsed template of the script's command.
+	tmpl *template.Template `yaml:"-"`
 
 	Name    string `yaml:"name"`
 	Command string `yaml:"command"`
+	Vars    VarSet `yaml:"vars,omitempty"`
 }
 
 // AddWorkspace is a function used to add a new workspace. This creates
@@ -243,16 +527,19 @@ func (c *Config) AddWorkspace(name, path string) error {
 	return nil
 }
 
-// GetWorkspace retrieves a workspace from config, with a matching path.
+// GetWorkspace retrieves a workspace from config, with a matching path,
+// resolved from the first store which defines it.
 func (c *Config) GetWorkspace(path string) (*Workspace, error) {
 	if path == "" {
 		return nil, ErrWorkspacePathEmpty
 	}
 
-	for _, w := range c.Workspaces {
-		if w.Path == path {
-			w.c = c
-			return w, nil
+	for _, l := range c.stack() {
+		for _, w := range l.Workspaces {
+			if w.Path == path {
+				w.c = c
+				return w, nil
+			}
 		}
 	}
 
@@ -292,6 +579,7 @@ func (w *Workspace) GetScript(name string) (*WorkspaceScript, error) {
 	for _, s := range w.Scripts {
 		if s.Name == name {
 			s.c = w.c
+			s.w = w
 			return s, nil
 		}
 	}
@@ -316,28 +604,19 @@ func (w *Workspace) RemoveScript(name string) error {
 	return ErrWorkspaceScriptNotFound
 }
 
-// Run executes the workplace script.
-func (s *WorkspaceScript) Run(cp CryptoProvider) (int, error) {
-	const secretPattern = "<secrets\\.([a-zA-Z0-9-_]+)>"
-	re := regexp.MustCompile(secretPattern)
-	cmdTxt := s.Command
-	for re.MatchString(cmdTxt) {
-		t := re.FindString(cmdTxt)
-		re2 := regexp.MustCompile(secretPattern)
-		sn := re2.FindStringSubmatch(t)[1]
-		s, err := s.c.GetSecret(sn)
-		if err == nil {
-			v := s.GetValue(cp)
-			cmdTxt = strings.ReplaceAll(cmdTxt, t, v)
-		}
-	}
-
-	args, err := splitCommandToArgs(cmdTxt)
+// Run executes the workplace script, with the given arguments. References
+// to secrets, variables, arguments and environment variables are resolved
+// using the config's active profile, if one is set. Secrets are read from
+// the workspace, then the config, then the workspace's team vault, if it
+// has one. If a secret's allow list doesn't include the script, it fails
+// to run, and if its use must be confirmed, the user is asked first.
+func (s *WorkspaceScript) Run(cp CryptoProvider, args ...string) (int, error) {
+	cmdArgs, err := s.commandArgs(cp, args)
 	if err != nil {
 		return -1, err
 	}
 
-	c := exec.Command(args[0], args[1:]...)
+	c := exec.Command(cmdArgs[0], cmdArgs[1:]...)
 	outRdr, _ := c.StdoutPipe()
 	errRdr, _ := c.StderrPipe()
 	err = c.Start()
@@ -370,68 +649,342 @@ func (s *WorkspaceScript) Run(cp CryptoProvider) (int, error) {
 	return state.ExitCode(), nil
 }
 
-// https://stackoverflow.com/a/46973603
+// commandArgs splits the script's command into arguments, before resolving
+// any references within them. This ensures resolved values are delivered
+// intact, as part of the argument they were referenced in.
+func (s *WorkspaceScript) commandArgs(cp CryptoProvider, args []string) ([]string, error) {
+	t, err := s.parse()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.activeProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	cmdArgs, err := t.ExecuteArgs(splitCommandToArgs, func(ref *template.Ref) (string, error) {
+		return s.resolve(ref, p, cp, args)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cmdArgs) < 1 {
+		return nil, ErrWorkspaceScriptCommandEmpty
+	}
+
+	return cmdArgs, nil
+}
+
+// Validate checks the script's command is a valid template.
+func (s *WorkspaceScript) Validate() error {
+	_, err := s.parse()
+	return err
+}
+
+// parse returns the script's command as a parsed template. The template
+// is cached, until the script's command is changed.
+func (s *WorkspaceScript) parse() (*template.Template, error) {
+	if s.tmpl != nil && s.tmpl.String() == s.Command {
+		return s.tmpl, nil
+	}
+
+	t, err := template.Parse(s.Command)
+	if err != nil {
+		return nil, err
+	}
+
+	s.tmpl = t
+
+	return t, nil
+}
+
+// resolve returns the value of the reference, ref, for the script. If the
+// value is empty, or not set, the reference's default value is used.
+func (s *WorkspaceScript) resolve(ref *template.Ref, p *Profile, cp CryptoProvider, args []string) (string, error) {
+	var v string
+	var ok bool
+	var errNotFound error
+
+	switch ref.Kind {
+	case template.Secret:
+		return s.secretValue(p.SecretName(ref.Name), ref.Name, cp)
+	case template.Var:
+		v, ok = s.lookupVar(p, ref.Name)
+		errNotFound = ErrVarNotFound
+	case template.Env:
+		v, ok = os.LookupEnv(ref.Name)
+		errNotFound = ErrEnvNotFound
+	case template.Arg:
+		v, ok = lookupArg(args, ref.Name)
+		errNotFound = ErrArgNotFound
+	}
+
+	if ref.HasDefault && v == "" {
+		return ref.Default, nil
+	}
+
+	if !ok {
+		return "", fmt.Errorf("%w: %s", errNotFound, ref.Name)
+	}
+
+	return v, nil
+}
+
+// secretValue returns the plain-text value of the secret with the given
+// name, referenced by ref, from the script's workspace, the config or the
+// workspace's team vault, in that order. Secrets in the config are only
+// read if the script is allowed to use them.
+func (s *WorkspaceScript) secretValue(name, ref string, cp CryptoProvider) (string, error) {
+	sec, err := s.getSecret(name)
+	if errors.Is(err, ErrSecretNotFound) {
+		v, err := s.teamSecret(name)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", err, ref)
+		}
+
+		return v, nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, ref)
+	}
+
+	err = s.c.checkExpiry(sec)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.checkAccess(sec)
+	if err != nil {
+		return "", err
+	}
+
+	return sec.GetValue(cp)
+}
+
+// getSecret returns the secret with the given name from the
+// script's workspace, otherwise, from the config.
+func (s *WorkspaceScript) getSecret(name string) (*Secret, error) {
+	if s.w != nil {
+		for _, sec := range s.w.Secrets {
+			if sec.Name == name {
+				return sec, nil
+			}
+		}
+	}
+
+	return s.c.GetSecret(name)
+}
+
+// lookupArg returns the argument at the 1-based position, name.
+func lookupArg(args []string, name string) (string, bool) {
+	i, err := strconv.Atoi(name)
+	if err != nil || i < 1 || i > len(args) {
+		return "", false
+	}
+
+	return args[i-1], true
+}
+
+// splitCommandToArgs splits a command into arguments, using the rules
+// of the host OS. On Windows, the rules of CommandLineToArgvW are used,
+// otherwise, those of a POSIX shell.
 func splitCommandToArgs(txt string) ([]string, error) {
+	if runtime.GOOS == "windows" {
+		return splitWindowsArgs(txt), nil
+	}
+
+	return splitPOSIXArgs(txt)
+}
+
+// splitPOSIXArgs splits txt into arguments, following the word splitting
+// and quote removal rules of a POSIX shell. No other expansions, such as
+// parameter expansion or globbing, are performed.
+//
+// Outside of quotes, a backslash preserves the literal value of the next
+// character, except a new line, which is removed. Within single quotes, all
+// characters are literal. Within double quotes, a backslash only escapes $,
+// `, ", \ and a new line. Quotes may appear anywhere within an argument,
+// and an empty pair of quotes results in an empty argument.
+func splitPOSIXArgs(txt string) ([]string, error) {
 	var args []string
-	state := "start"
-	current := ""
-	quote := "\""
-	escapeNext := true
+	var current strings.Builder
+	inArg := false
+
 	for i := 0; i < len(txt); i++ {
 		c := txt[i]
 
-		if state == "quotes" {
-			if string(c) != quote {
-				current += string(c)
-			} else {
-				args = append(args, current)
-				current = ""
-				state = "start"
+		switch c {
+		case ' ', '\t', '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case '\\':
+			if i+1 == len(txt) {
+				current.WriteByte(c)
+				inArg = true
+				continue
 			}
-			continue
-		}
 
-		if escapeNext {
-			current += string(c)
-			escapeNext = false
-			continue
+			i++
+			if txt[i] == '\n' {
+				continue
+			}
+
+			current.WriteByte(txt[i])
+			inArg = true
+		case '\'':
+			end := strings.IndexByte(txt[i+1:], '\'')
+			if end < 0 {
+				return nil, ErrWorkspaceScriptUnclosedQuote
+			}
+
+			current.WriteString(txt[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case '"':
+			closed := false
+			for i++; i < len(txt); i++ {
+				c = txt[i]
+				if c == '"' {
+					closed = true
+					break
+				}
+
+				if c == '\\' && i+1 < len(txt) {
+					switch txt[i+1] {
+					case '$', '`', '"', '\\':
+						i++
+						c = txt[i]
+					case '\n':
+						i++
+						continue
+					}
+				}
+
+				current.WriteByte(c)
+			}
+
+			if !closed {
+				return nil, ErrWorkspaceScriptUnclosedQuote
+			}
+
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
 		}
+	}
 
-		if c == '\\' {
-			escapeNext = true
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// splitWindowsArgs splits txt into arguments, following the rules used by
+// CommandLineToArgvW. Arguments are separated by spaces or tabs, and double
+// quotes may be used to include them in an argument. Within quotes, a pair
+// of double quotes results in a literal double quote.
+//
+// Backslashes are literal, unless they precede a double quote, in which case
+// each pair of backslashes results in a single backslash. If the number of
+// backslashes is odd, the double quote is also literal.
+//
+// The first argument, the program name, is treated differently, in that
+// backslashes are always literal, and double quotes only group characters.
+func splitWindowsArgs(txt string) []string {
+	var args []string
+	var current strings.Builder
+	i := 0
+
+	for i < len(txt) && isWindowsSpace(txt[i]) {
+		i++
+	}
+
+	if i == len(txt) {
+		return nil
+	}
+
+	inQuotes := false
+	for ; i < len(txt); i++ {
+		c := txt[i]
+		if c == '"' {
+			inQuotes = !inQuotes
 			continue
 		}
 
-		if c == '"' || c == '\'' {
-			state = "quotes"
-			quote = string(c)
-			continue
+		if !inQuotes && isWindowsSpace(c) {
+			break
 		}
 
-		if state == "arg" {
-			if c == ' ' || c == '\t' {
-				args = append(args, current)
-				current = ""
-				state = "start"
+		current.WriteByte(c)
+	}
+
+	args = append(args, current.String())
+	current.Reset()
+
+	inArg := false
+	inQuotes = false
+
+	for i < len(txt) {
+		c := txt[i]
+
+		switch {
+		case isWindowsSpace(c) && !inQuotes:
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+
+			i++
+		case c == '\\':
+			n := 0
+			for ; i < len(txt) && txt[i] == '\\'; i++ {
+				n++
+			}
+
+			if i < len(txt) && txt[i] == '"' {
+				current.WriteString(strings.Repeat("\\", n/2))
+				if n%2 == 1 {
+					current.WriteByte('"')
+					i++
+				}
 			} else {
-				current += string(c)
+				current.WriteString(strings.Repeat("\\", n))
+			}
+
+			inArg = true
+		case c == '"':
+			if inQuotes && i+1 < len(txt) && txt[i+1] == '"' {
+				current.WriteByte('"')
+				i += 2
+			} else {
+				inQuotes = !inQuotes
+				i++
 			}
-			continue
-		}
 
-		if c != ' ' && c != '\t' {
-			state = "arg"
-			current += string(c)
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+			i++
 		}
 	}
 
-	if state == "quotes" {
-		return []string{}, fmt.Errorf("unclosed quote in command: %s", txt)
+	if inArg {
+		args = append(args, current.String())
 	}
 
-	if current != "" {
-		args = append(args, current)
-	}
+	return args
+}
 
-	return args, nil
+func isWindowsSpace(c byte) bool {
+	return c == ' ' || c == '\t'
 }
@@ -0,0 +1,95 @@
+//go:build go1.18
+// +build go1.18
+
+package passport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// FuzzSplitPOSIXArgs ensures splitting never panics, and that the
+// arguments produced survive being quoted and split again.
+func FuzzSplitPOSIXArgs(f *testing.F) {
+	for _, tt := range posixSplitTests {
+		f.Add(tt.txt)
+	}
+
+	f.Fuzz(func(t *testing.T, txt string) {
+		args, err := splitPOSIXArgs(txt)
+		if err != nil {
+			return
+		}
+
+		quoted := make([]string, len(args))
+		for i, arg := range args {
+			quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+		}
+
+		again, err := splitPOSIXArgs(strings.Join(quoted, " "))
+		assert.NoError(t, err)
+		assert.Equal(t, args, again)
+	})
+}
+
+// FuzzSplitWindowsArgs ensures splitting never panics, and that the
+// arguments produced survive being escaped and split again.
+func FuzzSplitWindowsArgs(f *testing.F) {
+	for _, tt := range windowsSplitTests {
+		f.Add(tt.txt)
+	}
+
+	f.Fuzz(func(t *testing.T, txt string) {
+		args := splitWindowsArgs(txt)
+		if len(args) < 2 {
+			return
+		}
+
+		escaped := []string{"app"}
+		for _, arg := range args[1:] {
+			escaped = append(escaped, escapeWindowsArg(arg))
+		}
+
+		again := splitWindowsArgs(strings.Join(escaped, " "))
+		assert.Equal(t, args[1:], again[1:])
+	})
+}
+
+// escapeWindowsArg escapes s, so that it is parsed as a single
+// argument by CommandLineToArgvW.
+func escapeWindowsArg(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	quote := strings.ContainsAny(s, " \t\"")
+
+	var sb strings.Builder
+	if quote {
+		sb.WriteByte('"')
+	}
+
+	slashes := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			slashes++
+		case '"':
+			sb.WriteString(strings.Repeat(`\`, slashes+1))
+			slashes = 0
+		default:
+			slashes = 0
+		}
+
+		sb.WriteByte(s[i])
+	}
+
+	if quote {
+		sb.WriteString(strings.Repeat(`\`, slashes))
+		sb.WriteByte('"')
+	}
+
+	return sb.String()
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/reecerussell/passport/mock"
+	"github.com/reecerussell/passport/template"
 )
 
 func TestEnsureConfigFile(t *testing.T) {
@@ -71,7 +72,8 @@ func TestLoadConfig(t *testing.T) {
 	t.Run("Where Config File Exists", func(t *testing.T) {
 		testDir := ".config"
 		testFilePath := path.Join(testDir, configFilename)
-		testData := `secrets:
+		testData := `version: 1
+secrets:
 - name: MySecret
   value: Hello World
   secure: false`
@@ -79,7 +81,7 @@ func TestLoadConfig(t *testing.T) {
 		fs := mock.NewMockFilesys(ctrl)
 		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
 
-		c, err := LoadConfig(testDir, fs)
+		c, err := LoadConfig(testDir, fs, nil)
 		assert.NoError(t, err)
 		assert.Equal(t, 1, len(c.Secrets))
 		assert.Equal(t, "MySecret", c.Secrets[0].Name)
@@ -95,10 +97,80 @@ func TestLoadConfig(t *testing.T) {
 		fs := mock.NewMockFilesys(ctrl)
 		fs.EXPECT().Read(testFilePath).Return(nil, testErr)
 
-		c, err := LoadConfig(testDir, fs)
+		c, err := LoadConfig(testDir, fs, nil)
 		assert.Nil(t, c)
 		assert.Equal(t, testErr, err)
 	})
+
+	t.Run("Where Config File Is Not Valid YAML", func(t *testing.T) {
+		testDir := ".config"
+		testFilePath := path.Join(testDir, configFilename)
+		testData := `version: 1
+secrets:
+- name: MySecret
+  value: Hello: World
+workspaces: []`
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "line 4")
+	})
+
+	t.Run("Where Config File Has Wrong Types", func(t *testing.T) {
+		testDir := ".config"
+		testFilePath := path.Join(testDir, configFilename)
+		testData := `version: 1
+secrets:
+  name: MySecret`
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.Contains(t, err.Error(), "line 3")
+	})
+
+	t.Run("Where Config Is Invalid", func(t *testing.T) {
+		testDir := ".config"
+		testFilePath := path.Join(testDir, configFilename)
+		testData := `version: 1
+secrets:
+- name: MySecret
+  value: Hello World
+- name: MySecret
+  value: Hello World`
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, ErrSecretAlreadyExists)
+	})
+}
+
+func TestConfig_CheckNewSecret(t *testing.T) {
+	cnf := &Config{
+		Secrets: []*Secret{
+			{Name: "mySecret", Value: "Hello World"},
+		},
+	}
+
+	t.Run("Given New Name", func(t *testing.T) {
+		assert.NoError(t, cnf.CheckNewSecret("myOtherSecret"))
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		assert.Equal(t, ErrSecretNameEmpty, cnf.CheckNewSecret(""))
+	})
+
+	t.Run("Where Secret Exists", func(t *testing.T) {
+		assert.Equal(t, ErrSecretAlreadyExists, cnf.CheckNewSecret("mySecret"))
+	})
 }
 
 func TestConfig_AddSecret(t *testing.T) {
@@ -258,9 +330,10 @@ func TestConfig_Save(t *testing.T) {
 	defer ctrl.Finish()
 
 	t.Run("Saves Config", func(t *testing.T) {
-		testData := []byte("secrets: []\nworkspaces: []\n")
+		testData := []byte("version: 1\nsecrets: []\nworkspaces: []\n")
 
 		mockFilesys := mock.NewMockFilesys(ctrl)
+		mockFilesys.EXPECT().FileExists("config/"+configFilename).Return(false, nil)
 		mockFilesys.EXPECT().Write("config/"+configFilename, testData).Return(nil)
 
 		cnf := &Config{
@@ -275,10 +348,11 @@ func TestConfig_Save(t *testing.T) {
 	})
 
 	t.Run("Write Fails", func(t *testing.T) {
-		testData := []byte("secrets: []\nworkspaces: []\n")
+		testData := []byte("version: 1\nsecrets: []\nworkspaces: []\n")
 		testError := errors.New("filesys: test error")
 
 		mockFilesys := mock.NewMockFilesys(ctrl)
+		mockFilesys.EXPECT().FileExists("config/"+configFilename).Return(false, nil)
 		mockFilesys.EXPECT().Write("config/"+configFilename, testData).Return(testError)
 
 		cnf := &Config{
@@ -293,6 +367,78 @@ func TestConfig_Save(t *testing.T) {
 	})
 }
 
+func TestConfig_Update(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := "config"
+	testFilePath := path.Join(testDir, configFilename)
+	testLockPath := path.Join(testDir, configLockFilename)
+	testData := []byte("version: 1\nsecrets:\n- name: MySecret\n  value: Hello World\n")
+
+	t.Run("Reloads, Modifies And Saves Config", func(t *testing.T) {
+		unlocked := false
+		unlock := func() error {
+			unlocked = true
+			return nil
+		}
+
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().Lock(testLockPath).Return(unlock, nil),
+			fs.EXPECT().Read(testFilePath).Return(testData, nil),
+			fs.EXPECT().FileExists(testFilePath).Return(false, nil),
+			fs.EXPECT().Write(testFilePath, gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+				assert.False(t, unlocked)
+				assert.Contains(t, string(data), "name: MySecret")
+				assert.Contains(t, string(data), "name: MyOtherSecret")
+				return nil
+			}),
+		)
+
+		cnf := &Config{configDir: testDir, fs: fs}
+		err := cnf.Update(func(c *Config) error {
+			return c.AddSecret("MyOtherSecret", "value", false, nil)
+		})
+		assert.NoError(t, err)
+		assert.True(t, unlocked)
+		assert.Equal(t, 2, len(cnf.Secrets))
+	})
+
+	t.Run("Where Lock Fails", func(t *testing.T) {
+		testErr := errors.New("fs: error")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(nil, testErr)
+
+		cnf := &Config{configDir: testDir, fs: fs}
+		err := cnf.Update(func(c *Config) error {
+			t.Fatal("fn should not be called")
+			return nil
+		})
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("Where Fn Returns Error", func(t *testing.T) {
+		testErr := errors.New("update: error")
+		unlocked := false
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(func() error {
+			unlocked = true
+			return nil
+		}, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+
+		cnf := &Config{configDir: testDir, fs: fs}
+		err := cnf.Update(func(c *Config) error {
+			return testErr
+		})
+		assert.Equal(t, testErr, err)
+		assert.True(t, unlocked)
+	})
+}
+
 func TestSecret_GetValue(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -309,7 +455,8 @@ func TestSecret_GetValue(t *testing.T) {
 		cp := mock.NewMockCryptoProvider(ctrl)
 		cp.EXPECT().DecryptString(testSecureValue).Return(testValue, nil)
 
-		v := s.GetValue(cp)
+		v, err := s.GetValue(cp)
+		assert.NoError(t, err)
 		assert.Equal(t, testValue, v)
 	})
 
@@ -323,7 +470,8 @@ func TestSecret_GetValue(t *testing.T) {
 
 		cp := mock.NewMockCryptoProvider(ctrl)
 
-		v := s.GetValue(cp)
+		v, err := s.GetValue(cp)
+		assert.NoError(t, err)
 		assert.Equal(t, testValue, v)
 	})
 
@@ -339,8 +487,9 @@ func TestSecret_GetValue(t *testing.T) {
 		cp := mock.NewMockCryptoProvider(ctrl)
 		cp.EXPECT().DecryptString(testSecureValue).Return("", testError)
 
-		v := s.GetValue(cp)
+		v, err := s.GetValue(cp)
 		assert.Equal(t, "", v)
+		assert.Equal(t, testError, err)
 	})
 }
 
@@ -598,4 +747,240 @@ func TestWorkspaceScript_Run(t *testing.T) {
 		assert.NotNil(t, err)
 		assert.Equal(t, -1, code)
 	})
+
+	t.Run("Given Arguments", func(t *testing.T) {
+		command := "echo <args.1> <args.2:-none>"
+		if os.Getenv("GOOS") != "linux" {
+			command = "cmd /C " + command
+		}
+
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			panic(err)
+		}
+
+		oldStdout := os.Stdout
+		os.Stdout = pw
+
+		t.Cleanup(func() {
+			pw.Close()
+			os.Stdout = oldStdout
+		})
+
+		s := WorkspaceScript{
+			c:       &Config{},
+			Command: command,
+		}
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp, "v1.0.0")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+
+		pw.Close()
+		os.Stdout = oldStdout
+
+		bytes, _ := ioutil.ReadAll(pr)
+		assert.Contains(t, string(bytes), "v1.0.0 none")
+	})
+
+	t.Run("Given Quoted Argument Passed To Shell", func(t *testing.T) {
+		if os.Getenv("GOOS") != "linux" {
+			t.Skip("quote is only for POSIX shells")
+		}
+
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			panic(err)
+		}
+
+		oldStdout := os.Stdout
+		os.Stdout = pw
+
+		t.Cleanup(func() {
+			pw.Close()
+			os.Stdout = oldStdout
+		})
+
+		s := WorkspaceScript{
+			c:       &Config{},
+			Command: `sh -c "printf %s <args.1 | quote>"`,
+		}
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp, "it's; echo injected")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+
+		pw.Close()
+		os.Stdout = oldStdout
+
+		// The value is a single word to the shell, so isn't run by it.
+		bytes, _ := ioutil.ReadAll(pr)
+		assert.Equal(t, "it's; echo injected", string(bytes))
+	})
+
+	t.Run("Where Argument Is Missing", func(t *testing.T) {
+		s := WorkspaceScript{
+			c:       &Config{},
+			Command: "echo <args.1>",
+		}
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.ErrorIs(t, err, ErrArgNotFound)
+		assert.Equal(t, -1, code)
+	})
+
+	t.Run("Given Invalid Template", func(t *testing.T) {
+		s := WorkspaceScript{
+			Command: "echo <secrets.>",
+		}
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.ErrorIs(t, err, template.ErrNameEmpty)
+		assert.Equal(t, -1, code)
+	})
+}
+
+func TestWorkspaceScript_commandArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	values := []string{
+		"pass word",
+		`"double" 'single'`,
+		`back\slash\`,
+		"new\nline\ttab",
+		"",
+	}
+
+	for _, v := range values {
+		t.Run(v, func(t *testing.T) {
+			s := &WorkspaceScript{
+				c: &Config{
+					Secrets: []*Secret{
+						{
+							Name:   "password",
+							Value:  "encrypted",
+							Secure: true,
+						},
+					},
+				},
+				Command: "docker login --password <secrets.password> \"--prefix=<secrets.password>\"",
+			}
+
+			cp := mock.NewMockCryptoProvider(ctrl)
+			cp.EXPECT().DecryptString("encrypted").Return(v, nil).Times(2)
+
+			args, err := s.commandArgs(cp, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"docker", "login", "--password", v, "--prefix=" + v}, args)
+		})
+	}
+
+	t.Run("Given Empty Command", func(t *testing.T) {
+		s := &WorkspaceScript{
+			c:       &Config{},
+			Command: "",
+		}
+
+		args, err := s.commandArgs(mock.NewMockCryptoProvider(ctrl), nil)
+		assert.Nil(t, args)
+		assert.Equal(t, ErrWorkspaceScriptCommandEmpty, err)
+	})
+}
+
+// posixSplitTests is a corpus of commands, and the arguments they are
+// expected to be split into, following the rules of a POSIX shell.
+var posixSplitTests = []struct {
+	name string
+	txt  string
+	args []string
+}{
+	{"Empty", "", nil},
+	{"Whitespace Only", " \t\n ", nil},
+	{"Single Word", "echo", []string{"echo"}},
+	{"Multiple Words", "echo hello world", []string{"echo", "hello", "world"}},
+	{"Surrounding Whitespace", "  echo \t hello\n", []string{"echo", "hello"}},
+	{"First Character Escaped", `\"echo`, []string{`"echo`}},
+	{"Escaped Space", `echo hello\ world`, []string{"echo", "hello world"}},
+	{"Escaped Backslash", `echo a\\b`, []string{"echo", `a\b`}},
+	{"Escaped Letter", `echo \a`, []string{"echo", "a"}},
+	{"Line Continuation", "echo a \\\n b", []string{"echo", "a", "b"}},
+	{"Line Continuation Mid-Word", "echo a\\\nb", []string{"echo", "ab"}},
+	{"Trailing Backslash", `echo a\`, []string{"echo", `a\`}},
+	{"Single Quotes", `echo 'hello world'`, []string{"echo", "hello world"}},
+	{"Single Quotes Are Literal", `echo 'a\b "c" $d'`, []string{"echo", `a\b "c" $d`}},
+	{"Double Quotes", `echo "hello world"`, []string{"echo", "hello world"}},
+	{"Double Quote Escapes", `echo "\"a\" \\ \$b \` + "`" + `c\` + "`" + `"`, []string{"echo", `"a" \ $b ` + "`c`"}},
+	{"Double Quote Literal Backslash", `echo "a\b\n"`, []string{"echo", `a\b\n`}},
+	{"Double Quote Line Continuation", "echo \"a\\\nb\"", []string{"echo", "ab"}},
+	{"Double Quote New Line", "echo \"a\nb\"", []string{"echo", "a\nb"}},
+	{"Quotes Mid-Word", `--build-arg="name=reece"`, []string{"--build-arg=name=reece"}},
+	{"Adjacent Quotes", `a'b c'"d e"f`, []string{"ab cd ef"}},
+	{"Empty Single Quotes", `echo ''`, []string{"echo", ""}},
+	{"Empty Double Quotes", `echo "" x`, []string{"echo", "", "x"}},
+	{"Empty Quotes Mid-Word", `a""b`, []string{"ab"}},
+	{"Quote In Other Quotes", `echo "it's" 'say "hi"'`, []string{"echo", "it's", `say "hi"`}},
+	{"Unicode", "echo héllo ✓", []string{"echo", "héllo", "✓"}},
+}
+
+func TestSplitPOSIXArgs(t *testing.T) {
+	for _, tt := range posixSplitTests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := splitPOSIXArgs(tt.txt)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.args, args)
+		})
+	}
+
+	t.Run("Given Unclosed Quotes", func(t *testing.T) {
+		for _, txt := range []string{`echo 'a`, `echo "a`, `echo "a\"`, `echo a'`, `'`} {
+			args, err := splitPOSIXArgs(txt)
+			assert.Nil(t, args)
+			assert.Equal(t, ErrWorkspaceScriptUnclosedQuote, err, txt)
+		}
+	})
+}
+
+// windowsSplitTests is a corpus of commands, and the arguments they are
+// expected to be split into, following the rules of CommandLineToArgvW.
+var windowsSplitTests = []struct {
+	name string
+	txt  string
+	args []string
+}{
+	{"Empty", "", nil},
+	{"Whitespace Only", " \t ", nil},
+	{"Single Word", "cmd", []string{"cmd"}},
+	{"Multiple Words", "cmd /C echo", []string{"cmd", "/C", "echo"}},
+	{"New Line Is Not A Separator", "cmd a\nb", []string{"cmd", "a\nb"}},
+	{"Program Path Backslashes", `C:\tools\app.exe a\b`, []string{`C:\tools\app.exe`, `a\b`}},
+	{"Quoted Program Path", `"C:\Program Files\app.exe" x`, []string{`C:\Program Files\app.exe`, "x"}},
+	{"Program Path Trailing Backslash", `"C:\dir\" x`, []string{`C:\dir\`, "x"}},
+	{"Quoted Argument", `app "hello world"`, []string{"app", "hello world"}},
+	{"Quotes Mid-Word", `app --arg="a b"c`, []string{"app", "--arg=a bc"}},
+	{"Empty Quotes", `app "" x`, []string{"app", "", "x"}},
+	{"Escaped Quote", `app \"a`, []string{"app", `"a`}},
+	{"Even Backslashes Before Quote", `app a\\"b c"`, []string{"app", `a\b c`}},
+	{"Odd Backslashes Before Quote", `app a\\\"b`, []string{"app", `a\"b`}},
+	{"Backslashes Not Before Quote", `app a\\b\`, []string{"app", `a\\b\`}},
+	{"Double Quote In Quotes", `app "a""b" c`, []string{"app", `a"b`, "c"}},
+	{"Unclosed Quote", `app "a b`, []string{"app", "a b"}},
+	{"Single Quotes Are Literal", `app 'a b'`, []string{"app", "'a", "b'"}},
+}
+
+func TestSplitWindowsArgs(t *testing.T) {
+	for _, tt := range windowsSplitTests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := splitWindowsArgs(tt.txt)
+			assert.Equal(t, tt.args, args)
+		})
+	}
 }
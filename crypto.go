@@ -7,7 +7,11 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 
+	"filippo.io/age/armor"
 	"github.com/denisbrodbeck/machineid"
 )
 
@@ -23,6 +27,49 @@ type CryptoProvider interface {
 	DecryptString(value string) (string, error)
 }
 
+type lazyCryptoProvider struct {
+	once sync.Once
+	new  func() (CryptoProvider, error)
+	cp   CryptoProvider
+	err  error
+}
+
+// NewLazyCryptoProvider returns a CryptoProvider, which creates the
+// provider it uses with fn, when a value is first encrypted or decrypted.
+// This defers any cost, or prompt, of creating the provider until it's
+// needed. If fn returns an error, it's returned for every value.
+func NewLazyCryptoProvider(fn func() (CryptoProvider, error)) CryptoProvider {
+	return &lazyCryptoProvider{new: fn}
+}
+
+func (p *lazyCryptoProvider) provider() (CryptoProvider, error) {
+	p.once.Do(func() {
+		p.cp, p.err = p.new()
+	})
+
+	return p.cp, p.err
+}
+
+// EncryptString encrypts value using the provider, once created.
+func (p *lazyCryptoProvider) EncryptString(value string) (string, error) {
+	cp, err := p.provider()
+	if err != nil {
+		return "", err
+	}
+
+	return cp.EncryptString(value)
+}
+
+// DecryptString decrypts value using the provider, once created.
+func (p *lazyCryptoProvider) DecryptString(value string) (string, error) {
+	cp, err := p.provider()
+	if err != nil {
+		return "", err
+	}
+
+	return cp.DecryptString(value)
+}
+
 type hostCryptoProvider struct{}
 
 // NewCryptoProvider returns a new instance of CryptoProvider.
@@ -50,8 +97,13 @@ func (p *hostCryptoProvider) EncryptString(value string) (string, error) {
 // DecryptString decrypts a string value using AES256, with a
 // key generated from a host machine's unique identifier. If
 // value is invalid or cannot be decrypted, ErrDecryptFailed
-// will be returned.
+// will be returned. Values encrypted by the age providers
+// are detected, so the error can say so.
 func (p *hostCryptoProvider) DecryptString(value string) (string, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), armor.Header) {
+		return "", fmt.Errorf("%w: the value was encrypted by age, not the host provider", ErrDecryptFailed)
+	}
+
 	key, err := p.generateEncryptionKey()
 	if err != nil {
 		return "", err
@@ -60,7 +112,11 @@ func (p *hostCryptoProvider) DecryptString(value string) (string, error) {
 	c, _ := aes.NewCipher(key)
 	gcm, _ := cipher.NewGCM(c)
 	nonceSize := gcm.NonceSize()
-	cipherText, _ := base64.StdEncoding.DecodeString(value)
+	cipherText, err := base64.StdEncoding.DecodeString(value)
+	if err != nil || len(cipherText) < nonceSize {
+		return "", ErrDecryptFailed
+	}
+
 	nonce, cipherText := cipherText[:nonceSize], cipherText[nonceSize:]
 	plainText, err := gcm.Open(nil, nonce, cipherText, nil)
 	if err != nil {
@@ -0,0 +1,120 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// Common age errors.
+var (
+	ErrAgeNoRecipients = errors.New("age: no recipients to encrypt to")
+	ErrAgeNoIdentities = errors.New("age: no identities to decrypt with")
+	ErrAgeIdentityFile = errors.New("age: no identity file given")
+)
+
+type ageCryptoProvider struct {
+	recipients []age.Recipient
+	identities []age.Identity
+}
+
+// NewAgeCryptoProvider returns a new CryptoProvider, which encrypts values
+// to the recipients, in the ASCII armored age format, and decrypts them
+// using any of the identities. Values can be decrypted by the age CLI,
+// and values encrypted by it, with --armor, can be decrypted.
+func NewAgeCryptoProvider(recipients []age.Recipient, identities []age.Identity) CryptoProvider {
+	return &ageCryptoProvider{
+		recipients: recipients,
+		identities: identities,
+	}
+}
+
+// LoadAgeCryptoProvider returns an age CryptoProvider, which decrypts values
+// using the identities in the file, in the format written by age-keygen.
+// Values are encrypted to the public keys of the identities, along with any
+// other recipients given. If identityFile is empty, ErrAgeIdentityFile
+// is returned.
+func LoadAgeCryptoProvider(identityFile string, recipients []string, fs Filesys) (CryptoProvider, error) {
+	if identityFile == "" {
+		return nil, ErrAgeIdentityFile
+	}
+
+	data, err := fs.Read(expandPath(identityFile))
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := age.ParseIdentities(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIdentityInvalid, err.Error())
+	}
+
+	rs, err := parseRecipients(recipients)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range identities {
+		if x, ok := id.(*age.X25519Identity); ok {
+			rs = append(rs, x.Recipient())
+		}
+	}
+
+	return NewAgeCryptoProvider(rs, identities), nil
+}
+
+// NewAgePassphraseCryptoProvider returns an age CryptoProvider, which
+// encrypts and decrypts values using a key derived from the passphrase,
+// using scrypt. This is deliberately slow, taking around a second for
+// each value.
+func NewAgePassphraseCryptoProvider(passphrase string) (CryptoProvider, error) {
+	r, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAgeCryptoProvider([]age.Recipient{r}, []age.Identity{id}), nil
+}
+
+// EncryptString encrypts value to each of the provider's recipients.
+func (p *ageCryptoProvider) EncryptString(value string) (string, error) {
+	if len(p.recipients) == 0 {
+		return "", ErrAgeNoRecipients
+	}
+
+	data, err := encryptArmored([]byte(value), p.recipients...)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
+
+// DecryptString decrypts value, which must be ASCII armored, using the
+// provider's identities. If value cannot be decrypted, ErrDecryptFailed
+// is returned.
+func (p *ageCryptoProvider) DecryptString(value string) (string, error) {
+	if len(p.identities) == 0 {
+		return "", ErrAgeNoIdentities
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(value), armor.Header) {
+		return "", fmt.Errorf("%w: the value wasn't encrypted by age, it may have been encrypted by the host provider", ErrDecryptFailed)
+	}
+
+	data, err := decryptArmored([]byte(value), p.identities...)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptFailed, err.Error())
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,181 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestAgeCryptoProvider(t *testing.T) {
+	const testValue = "Hello World"
+
+	id, _ := age.GenerateX25519Identity()
+	cp := NewAgeCryptoProvider([]age.Recipient{id.Recipient()}, []age.Identity{id})
+
+	t.Run("Given Value", func(t *testing.T) {
+		v, err := cp.EncryptString(testValue)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(v, "-----BEGIN AGE ENCRYPTED FILE-----\n"))
+
+		plain, err := cp.DecryptString(v)
+		assert.NoError(t, err)
+		assert.Equal(t, testValue, plain)
+	})
+
+	t.Run("Given Value Encrypted By Age", func(t *testing.T) {
+		var buf bytes.Buffer
+		aw := armor.NewWriter(&buf)
+		w, _ := age.Encrypt(aw, id.Recipient())
+		w.Write([]byte(testValue))
+		w.Close()
+		aw.Close()
+
+		plain, err := cp.DecryptString(buf.String())
+		assert.NoError(t, err)
+		assert.Equal(t, testValue, plain)
+	})
+
+	t.Run("Given Value Decrypted By Age", func(t *testing.T) {
+		v, _ := cp.EncryptString(testValue)
+
+		r, err := age.Decrypt(armor.NewReader(strings.NewReader(v)), id)
+		assert.NoError(t, err)
+
+		plain, _ := ioutil.ReadAll(r)
+		assert.Equal(t, testValue, string(plain))
+	})
+
+	t.Run("Given Value For Other Identity", func(t *testing.T) {
+		other, _ := age.GenerateX25519Identity()
+		v, _ := NewAgeCryptoProvider([]age.Recipient{other.Recipient()}, nil).EncryptString(testValue)
+
+		plain, err := cp.DecryptString(v)
+		assert.Equal(t, "", plain)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+	})
+
+	t.Run("Given Invalid Value", func(t *testing.T) {
+		plain, err := cp.DecryptString("abc123")
+		assert.Equal(t, "", plain)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+	})
+
+	t.Run("Given Value Encrypted By Host", func(t *testing.T) {
+		v, _ := NewCryptoProvider().EncryptString(testValue)
+
+		plain, err := cp.DecryptString(v)
+		assert.Equal(t, "", plain)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+		assert.Contains(t, err.Error(), "host provider")
+	})
+
+	t.Run("Given No Recipients", func(t *testing.T) {
+		_, err := NewAgeCryptoProvider(nil, nil).EncryptString(testValue)
+		assert.Equal(t, ErrAgeNoRecipients, err)
+	})
+
+	t.Run("Given No Identities", func(t *testing.T) {
+		_, err := NewAgeCryptoProvider(nil, nil).DecryptString(testValue)
+		assert.Equal(t, ErrAgeNoIdentities, err)
+	})
+}
+
+func TestLoadAgeCryptoProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	id, _ := age.GenerateX25519Identity()
+	other, _ := age.GenerateX25519Identity()
+	keyFile := "# created: 2021-05-01T12:00:00Z\n" +
+		"# public key: " + id.Recipient().String() + "\n" +
+		id.String() + "\n"
+
+	t.Run("Given Identity File", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read("/keys/key.txt").Return([]byte(keyFile), nil)
+
+		cp, err := LoadAgeCryptoProvider("/keys/key.txt", []string{other.Recipient().String()}, fs)
+		assert.NoError(t, err)
+
+		v, err := cp.EncryptString("Hello World")
+		assert.NoError(t, err)
+
+		plain, err := cp.DecryptString(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello World", plain)
+
+		// The value is also encrypted to the other recipient.
+		plain, err = NewAgeCryptoProvider(nil, []age.Identity{other}).DecryptString(v)
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello World", plain)
+	})
+
+	t.Run("Given Invalid Identity File", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read("/keys/key.txt").Return([]byte("invalid\n"), nil)
+
+		cp, err := LoadAgeCryptoProvider("/keys/key.txt", nil, fs)
+		assert.Nil(t, cp)
+		assert.True(t, errors.Is(err, ErrIdentityInvalid))
+	})
+
+	t.Run("Given Invalid Recipient", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read("/keys/key.txt").Return([]byte(keyFile), nil)
+
+		cp, err := LoadAgeCryptoProvider("/keys/key.txt", []string{"invalid"}, fs)
+		assert.Nil(t, cp)
+		assert.True(t, errors.Is(err, ErrRecipientInvalid))
+	})
+
+	t.Run("Where Read Fails", func(t *testing.T) {
+		testErr := errors.New("an error occurred")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read("/keys/key.txt").Return(nil, testErr)
+
+		cp, err := LoadAgeCryptoProvider("/keys/key.txt", nil, fs)
+		assert.Nil(t, cp)
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("Given No Identity File", func(t *testing.T) {
+		cp, err := LoadAgeCryptoProvider("", nil, mock.NewMockFilesys(ctrl))
+		assert.Nil(t, cp)
+		assert.Equal(t, ErrAgeIdentityFile, err)
+	})
+}
+
+func TestNewAgePassphraseCryptoProvider(t *testing.T) {
+	cp, err := NewAgePassphraseCryptoProvider("correct horse battery staple")
+	assert.NoError(t, err)
+
+	v, err := cp.EncryptString("Hello World")
+	assert.NoError(t, err)
+
+	plain, err := cp.DecryptString(v)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello World", plain)
+
+	t.Run("Given Wrong Passphrase", func(t *testing.T) {
+		id, _ := age.NewScryptIdentity("wrong")
+		plain, err := NewAgeCryptoProvider(nil, []age.Identity{id}).DecryptString(v)
+		assert.Equal(t, "", plain)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+	})
+
+	t.Run("Given Empty Passphrase", func(t *testing.T) {
+		cp, err := NewAgePassphraseCryptoProvider("")
+		assert.Nil(t, cp)
+		assert.Error(t, err)
+	})
+}
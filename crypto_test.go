@@ -4,12 +4,61 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"encoding/base64"
+	"errors"
 	"math/rand"
 	"testing"
 
+	"filippo.io/age"
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
 )
 
+func TestLazyCryptoProvider(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("Creates Provider Once, When Used", func(t *testing.T) {
+		inner := mock.NewMockCryptoProvider(ctrl)
+		inner.EXPECT().EncryptString("Hello").Return("encrypted", nil)
+		inner.EXPECT().DecryptString("encrypted").Return("Hello", nil)
+
+		calls := 0
+		cp := NewLazyCryptoProvider(func() (CryptoProvider, error) {
+			calls++
+			return inner, nil
+		})
+		assert.Equal(t, 0, calls)
+
+		v, err := cp.EncryptString("Hello")
+		assert.NoError(t, err)
+		assert.Equal(t, "encrypted", v)
+
+		v, err = cp.DecryptString("encrypted")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", v)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("Where Provider Fails To Be Created", func(t *testing.T) {
+		testErr := errors.New("an error occurred")
+
+		calls := 0
+		cp := NewLazyCryptoProvider(func() (CryptoProvider, error) {
+			calls++
+			return nil, testErr
+		})
+
+		_, err := cp.EncryptString("Hello")
+		assert.Equal(t, testErr, err)
+
+		_, err = cp.DecryptString("encrypted")
+		assert.Equal(t, testErr, err)
+		assert.Equal(t, 1, calls)
+	})
+}
+
 func TestHostCryptoProvider(t *testing.T) {
 	const testValue = "Hello World"
 
@@ -55,4 +104,26 @@ func TestHostCryptoProvider_DecryptString(t *testing.T) {
 		assert.Equal(t, "", result)
 		assert.Equal(t, ErrDecryptFailed, err)
 	})
+
+	t.Run("Where Value Is Not Base64", func(t *testing.T) {
+		result, err := cp.DecryptString("not base64!")
+		assert.Equal(t, "", result)
+		assert.Equal(t, ErrDecryptFailed, err)
+	})
+
+	t.Run("Where Value Is Too Short", func(t *testing.T) {
+		result, err := cp.DecryptString(base64.StdEncoding.EncodeToString([]byte("short")))
+		assert.Equal(t, "", result)
+		assert.Equal(t, ErrDecryptFailed, err)
+	})
+
+	t.Run("Where Value Was Encrypted By Age", func(t *testing.T) {
+		id, _ := age.GenerateX25519Identity()
+		encValue, _ := NewAgeCryptoProvider([]age.Recipient{id.Recipient()}, nil).EncryptString("Hello World")
+
+		result, err := cp.DecryptString(encValue)
+		assert.Equal(t, "", result)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+		assert.Contains(t, err.Error(), "encrypted by age")
+	})
 }
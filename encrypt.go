@@ -0,0 +1,152 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// encryptedConfigHeader is the first line of an encrypted config file,
+// which is followed by the config document, encrypted by the CryptoProvider.
+const encryptedConfigHeader = "passport:encrypted:v1\n"
+
+// Common config encryption errors.
+var (
+	ErrConfigCryptoProviderNil = errors.New("config: a crypto provider is required to encrypt or decrypt the config")
+	ErrConfigAlreadyEncrypted  = errors.New("config: already encrypted")
+	ErrConfigNotEncrypted      = errors.New("config: not encrypted")
+)
+
+// IsEncrypted returns true if the config file is encrypted at rest.
+func (c *Config) IsEncrypted() bool {
+	return c.encrypted
+}
+
+// SetEncrypted encrypts, or decrypts, the entire config file, using the
+// config's CryptoProvider. Once encrypted, the config is encrypted each time
+// it is saved, until SetEncrypted is called with false. When encrypting,
+// existing backups of the config are also encrypted, so no plain-text
+// copies are left. If the config is already in the given mode,
+// ErrConfigAlreadyEncrypted or ErrConfigNotEncrypted is returned.
+func (c *Config) SetEncrypted(encrypted bool) error {
+	if c.cp == nil {
+		return ErrConfigCryptoProviderNil
+	}
+
+	unlock, err := c.fs.Lock(path.Join(c.configDir, configLockFilename))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	err = c.load()
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case encrypted && c.encrypted:
+		return ErrConfigAlreadyEncrypted
+	case !encrypted && !c.encrypted:
+		return ErrConfigNotEncrypted
+	}
+
+	c.encrypted = encrypted
+
+	err = c.Save()
+	if err != nil || !encrypted {
+		return err
+	}
+
+	return c.encryptBackups()
+}
+
+// encryptBackups encrypts each of the config's backups, and the backups
+// taken before it was migrated, which are not already encrypted.
+func (c *Config) encryptBackups() error {
+	backups, err := c.Backups()
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+
+	names, err := c.fs.List(c.configDir)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if strings.HasPrefix(name, configFilename+".v") && strings.HasSuffix(name, ".bak") {
+			paths = append(paths, path.Join(c.configDir, name))
+		}
+	}
+
+	for _, p := range paths {
+		data, err := c.fs.Read(p)
+		if err != nil {
+			return err
+		}
+
+		if bytes.HasPrefix(data, []byte(encryptedConfigHeader)) {
+			continue
+		}
+
+		data, err = c.encode(data)
+		if err != nil {
+			return err
+		}
+
+		err = c.fs.Write(p, data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encode returns data, encrypted with the config's CryptoProvider, if the
+// config is encrypted. Otherwise, data is returned as is.
+func (c *Config) encode(data []byte) ([]byte, error) {
+	if !c.encrypted {
+		return data, nil
+	}
+
+	if c.cp == nil {
+		return nil, ErrConfigCryptoProviderNil
+	}
+
+	v, err := c.cp.EncryptString(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(encryptedConfigHeader + v + "\n"), nil
+}
+
+// decode returns the config document in data, read from filePath,
+// decrypting it if the file is encrypted, along with whether it was.
+func (c *Config) decode(filePath string, data []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(data, []byte(encryptedConfigHeader)) {
+		return data, false, nil
+	}
+
+	if c.cp == nil {
+		return nil, true, ErrConfigCryptoProviderNil
+	}
+
+	v := bytes.TrimSpace(data[len(encryptedConfigHeader):])
+	plain, err := c.cp.DecryptString(string(v))
+	if err != nil {
+		return nil, true, fmt.Errorf("config: failed to decrypt %s: %w", filePath, err)
+	}
+
+	return []byte(plain), true, nil
+}
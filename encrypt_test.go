@@ -0,0 +1,225 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestLoadConfig_Encrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+	testData := "version: 1\nsecrets:\n- name: MySecret\n  value: Hello World\n"
+
+	t.Run("Where Config File Is Encrypted", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(encryptedConfigHeader+"abc123\n"), nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("abc123").Return(testData, nil)
+
+		c, err := LoadConfig(testDir, fs, cp)
+		assert.NoError(t, err)
+		assert.True(t, c.IsEncrypted())
+		assert.Equal(t, "Hello World", c.Secrets[0].Value)
+	})
+
+	t.Run("Where Config File Is Not Encrypted", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+
+		c, err := LoadConfig(testDir, fs, mock.NewMockCryptoProvider(ctrl))
+		assert.NoError(t, err)
+		assert.False(t, c.IsEncrypted())
+	})
+
+	t.Run("Where Decrypt Fails", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(encryptedConfigHeader+"abc123\n"), nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("abc123").Return("", ErrDecryptFailed)
+
+		c, err := LoadConfig(testDir, fs, cp)
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, ErrDecryptFailed)
+	})
+
+	t.Run("Given Nil Crypto Provider", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(encryptedConfigHeader+"abc123\n"), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.Equal(t, ErrConfigCryptoProviderNil, err)
+	})
+}
+
+func TestConfig_Save_Encrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+
+	t.Run("Writes Encrypted Document", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString("version: 1\nsecrets: []\nworkspaces: []\n").Return("abc123", nil)
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(false, nil)
+		fs.EXPECT().Write(testFilePath, []byte(encryptedConfigHeader+"abc123\n")).Return(nil)
+
+		c := &Config{
+			configDir:  testDir,
+			fs:         fs,
+			cp:         cp,
+			encrypted:  true,
+			Secrets:    []*Secret{},
+			Workspaces: []*Workspace{},
+		}
+		err := c.Save()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Encrypt Fails", func(t *testing.T) {
+		testErr := errors.New("crypto: error")
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString(gomock.Any()).Return("", testErr)
+
+		c := &Config{
+			configDir: testDir,
+			fs:        mock.NewMockFilesys(ctrl),
+			cp:        cp,
+			encrypted: true,
+		}
+		err := c.Save()
+		assert.Equal(t, testErr, err)
+	})
+}
+
+func TestConfig_SetEncrypted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+	testLockPath := path.Join(testDir, configLockFilename)
+	testData := "version: 1\nsecrets: []\nworkspaces: []\n"
+	unlock := func() error { return nil }
+
+	t.Run("Encrypts Config", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString(testData).Return("abc123", nil)
+
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().Lock(testLockPath).Return(unlock, nil),
+			fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil),
+			fs.EXPECT().FileExists(testFilePath).Return(false, nil),
+			fs.EXPECT().Write(testFilePath, []byte(encryptedConfigHeader+"abc123\n")).Return(nil),
+			fs.EXPECT().List(path.Join(testDir, backupDirname)).Return(nil, ErrDirNotExists),
+			fs.EXPECT().List(testDir).Return([]string{configFilename}, nil),
+		)
+
+		c := &Config{configDir: testDir, fs: fs, cp: cp}
+		err := c.SetEncrypted(true)
+		assert.NoError(t, err)
+		assert.True(t, c.IsEncrypted())
+	})
+
+	t.Run("Encrypts Backups", func(t *testing.T) {
+		id, _ := age.GenerateX25519Identity()
+		cp := NewAgeCryptoProvider([]age.Recipient{id.Recipient()}, []age.Identity{id})
+
+		dir := t.TempDir()
+		fs := NewFilesys()
+		assert.NoError(t, EnsureConfigFile(dir, fs))
+
+		c, err := LoadConfig(dir, fs, cp)
+		assert.NoError(t, err)
+		assert.NoError(t, c.AddSecret("Token", "plain-text-value", false, nil))
+		assert.NoError(t, c.Save())
+
+		migrationBackup := path.Join(dir, configFilename+".v0.bak")
+		assert.NoError(t, fs.Write(migrationBackup, []byte("secrets:\n- name: Token\n  value: old-value\n")))
+
+		assert.NoError(t, c.SetEncrypted(true))
+
+		backups, err := c.Backups()
+		assert.NoError(t, err)
+		assert.NotEmpty(t, backups)
+
+		paths := []string{migrationBackup}
+		for _, b := range backups {
+			paths = append(paths, b.path)
+		}
+
+		for _, p := range paths {
+			data, err := fs.Read(p)
+			assert.NoError(t, err)
+			assert.True(t, bytes.HasPrefix(data, []byte(encryptedConfigHeader)), p)
+			assert.NotContains(t, string(data), "value")
+		}
+
+		assert.NoError(t, c.RestoreBackup(backups[len(backups)-1].ID))
+	})
+
+	t.Run("Decrypts Config", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("abc123").Return(testData, nil)
+
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().Lock(testLockPath).Return(unlock, nil),
+			fs.EXPECT().Read(testFilePath).Return([]byte(encryptedConfigHeader+"abc123\n"), nil),
+			fs.EXPECT().FileExists(testFilePath).Return(false, nil),
+			fs.EXPECT().Write(testFilePath, []byte(testData)).Return(nil),
+		)
+
+		c := &Config{configDir: testDir, fs: fs, cp: cp, encrypted: true}
+		err := c.SetEncrypted(false)
+		assert.NoError(t, err)
+		assert.False(t, c.IsEncrypted())
+	})
+
+	t.Run("Where Config Is Already Encrypted", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("abc123").Return(testData, nil)
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().Read(testFilePath).Return([]byte(encryptedConfigHeader+"abc123\n"), nil)
+
+		c := &Config{configDir: testDir, fs: fs, cp: cp}
+		err := c.SetEncrypted(true)
+		assert.Equal(t, ErrConfigAlreadyEncrypted, err)
+	})
+
+	t.Run("Where Config Is Not Encrypted", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Lock(testLockPath).Return(unlock, nil)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+
+		c := &Config{configDir: testDir, fs: fs, cp: mock.NewMockCryptoProvider(ctrl)}
+		err := c.SetEncrypted(false)
+		assert.Equal(t, ErrConfigNotEncrypted, err)
+	})
+
+	t.Run("Given Nil Crypto Provider", func(t *testing.T) {
+		c := &Config{configDir: testDir, fs: mock.NewMockFilesys(ctrl)}
+		err := c.SetEncrypted(true)
+		assert.Equal(t, ErrConfigCryptoProviderNil, err)
+	})
+}
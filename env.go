@@ -0,0 +1,53 @@
+package passport
+
+import (
+	"sort"
+)
+
+// Env returns the environment of the workspace, for the config's active
+// profile, sorted by name. This includes each variable, resolved in the
+// same order as a script's, and the plain-text value of each of the
+// profile's secrets, named by their reference, which are resolved in the
+// same order as a script's.
+func (w *Workspace) Env(cp CryptoProvider) ([]*SecretValue, error) {
+	s := &WorkspaceScript{c: w.c, w: w}
+	p, err := s.activeProfile()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(VarSet)
+	sets := []VarSet{p.Vars, w.Vars}
+	if w.c != nil {
+		for _, l := range w.c.stack() {
+			sets = append(sets, l.Vars)
+		}
+	}
+
+	for _, vs := range sets {
+		for name := range vs {
+			names[name] = ""
+		}
+	}
+
+	values := secretValues{}
+	for _, name := range names.Names() {
+		v, _ := s.lookupVar(p, name)
+		values.set(name, v)
+	}
+
+	for ref, name := range p.Secrets {
+		v, err := s.secretValue(name, ref, cp)
+		if err != nil {
+			return nil, err
+		}
+
+		values.set(ref, v)
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+
+	return values, nil
+}
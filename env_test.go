@@ -0,0 +1,81 @@
+package passport
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestWorkspace_Env(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cnf := &Config{
+		Secrets: []*Secret{
+			{Name: "staging-password", Value: "encrypted", Secure: true},
+		},
+		Vars: VarSet{"REGION": "eu-west-1", "HOST": "global"},
+		Profiles: ProfileSet{
+			{
+				Name:    "staging",
+				Vars:    VarSet{"TAG": "staging"},
+				Secrets: map[string]string{"PASSWORD": "staging-password"},
+			},
+		},
+	}
+	w := &Workspace{
+		c:    cnf,
+		Vars: VarSet{"HOST": "workspace"},
+	}
+
+	t.Run("Given No Active Profile", func(t *testing.T) {
+		values, err := w.Env(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "HOST", Value: "workspace"},
+			{Name: "REGION", Value: "eu-west-1"},
+		}, values)
+	})
+
+	t.Run("Given Active Profile", func(t *testing.T) {
+		cnf.UseProfile("staging")
+		defer cnf.UseProfile("")
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return("staging123", nil)
+
+		values, err := w.Env(cp)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "HOST", Value: "workspace"},
+			{Name: "PASSWORD", Value: "staging123"},
+			{Name: "REGION", Value: "eu-west-1"},
+			{Name: "TAG", Value: "staging"},
+		}, values)
+	})
+
+	t.Run("Where Profile Does Not Exist", func(t *testing.T) {
+		cnf.UseProfile("prod")
+		defer cnf.UseProfile("")
+
+		values, err := w.Env(nil)
+		assert.Nil(t, values)
+		assert.Equal(t, ErrProfileNotFound, err)
+	})
+
+	t.Run("Where Profile Secret Does Not Exist", func(t *testing.T) {
+		c := &Config{
+			Profiles: ProfileSet{
+				{Name: "dev", Secrets: map[string]string{"PASSWORD": "dev-password"}},
+			},
+		}
+		c.UseProfile("dev")
+
+		values, err := (&Workspace{c: c}).Env(nil)
+		assert.Nil(t, values)
+		assert.Equal(t, "secret: not found: PASSWORD", err.Error())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -20,7 +21,8 @@ type Filesys interface {
 	EnsureDirectory(path string) error
 
 	// Write writes data to path. If the files does not exist,
-	// it will be created, otherwise overwritten.
+	// it will be created, otherwise overwritten. Writes are atomic,
+	// in that path will never contain partially written data.
 	Write(path string, data []byte) error
 
 	// FileExists returns a boolean which determines if a file
@@ -29,6 +31,17 @@ type Filesys interface {
 
 	// Read reads all data from a file at path.
 	Read(path string) ([]byte, error)
+
+	// List returns the names of the files in the directory at path.
+	List(path string) ([]string, error)
+
+	// Remove removes the file at path.
+	Remove(path string) error
+
+	// Lock acquires an exclusive, advisory lock on the file at path,
+	// creating it if it does not exist, blocking until the lock is
+	// acquired. The returned function is used to release the lock.
+	Lock(path string) (func() error, error)
 }
 
 type osFilesys struct{}
@@ -50,24 +63,44 @@ func (*osFilesys) EnsureDirectory(path string) error {
 	return os.MkdirAll(path, os.ModePerm)
 }
 
-// Write writes data to dir+file. If the file does not
-// exist, it will be created, otherwise overwritten.
+// Write atomically writes data to path, by writing it to a temporary
+// file in the same directory, then renaming it to path. If the file
+// already exists, it will be replaced, retaining its permissions.
 func (*osFilesys) Write(path string, data []byte) error {
 	if path == "" {
 		return ErrPathEmpty
 	}
 
-	err := os.WriteFile(path, data, 0666)
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
 	if err != nil {
-		pe := err.(*os.PathError)
-		if pe.Op == "open" {
+		if errors.Is(err, os.ErrNotExist) {
 			return ErrDirNotExists
 		}
 
 		return err
 	}
 
-	return nil
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+
+	if info, err := os.Stat(path); err == nil {
+		f.Chmod(info.Mode())
+	}
+
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
 }
 
 // FileExists returns a boolean which indicates whether a file
@@ -98,3 +131,74 @@ func (*osFilesys) Read(path string) ([]byte, error) {
 
 	return ioutil.ReadFile(path)
 }
+
+// List returns the names of the files in the directory at path, in
+// order. Sub-directories are not included. If the directory does not
+// exist, ErrDirNotExists is returned.
+func (*osFilesys) List(path string) ([]string, error) {
+	if path == "" {
+		return nil, ErrPathEmpty
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrDirNotExists
+		}
+
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// Remove removes the file at path. This is a wrapper around os.Remove.
+func (*osFilesys) Remove(path string) error {
+	if path == "" {
+		return ErrPathEmpty
+	}
+
+	return os.Remove(path)
+}
+
+// Lock acquires an exclusive, advisory lock on the file at path, creating
+// it if it does not exist. As the lock is advisory, it only prevents other
+// callers of Lock from acquiring a lock on the same file.
+func (*osFilesys) Lock(path string) (func() error, error) {
+	if path == "" {
+		return nil, ErrPathEmpty
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrDirNotExists
+		}
+
+		return nil, err
+	}
+
+	err = lockFile(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	unlock := func() error {
+		err := unlockFile(f)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+
+		return err
+	}
+
+	return unlock, nil
+}
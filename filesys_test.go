@@ -3,7 +3,9 @@ package passport
 import (
 	"io/ioutil"
 	"os"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -107,6 +109,20 @@ func TestOsFilesys_Write(t *testing.T) {
 		err := fs.Write(testPath, testData)
 		assert.Equal(t, ErrDirNotExists, err)
 	})
+
+	t.Run("Does Not Leave Temporary Files", func(t *testing.T) {
+		testDir := t.TempDir()
+		testPath := path.Join(testDir, "TestOsFilesys_Write4")
+
+		fs := NewFilesys()
+		err := fs.Write(testPath, []byte("Hello World"))
+		assert.NoError(t, err)
+
+		entries, err := os.ReadDir(testDir)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(entries))
+		assert.Equal(t, "TestOsFilesys_Write4", entries[0].Name())
+	})
 }
 
 func TestOsFilesys_FileExists(t *testing.T) {
@@ -175,3 +191,108 @@ func TestOsFilesys_Read(t *testing.T) {
 		assert.Equal(t, testData, data)
 	})
 }
+
+func TestOsFilesys_List(t *testing.T) {
+	t.Run("Given Empty Path", func(t *testing.T) {
+		fs := NewFilesys()
+		names, err := fs.List("")
+		assert.Nil(t, names)
+		assert.Equal(t, ErrPathEmpty, err)
+	})
+
+	t.Run("Where Directory Does Not Exist", func(t *testing.T) {
+		fs := NewFilesys()
+		names, err := fs.List("TestOsFilesys_List1")
+		assert.Nil(t, names)
+		assert.Equal(t, ErrDirNotExists, err)
+	})
+
+	t.Run("Given Valid Path", func(t *testing.T) {
+		testDir := t.TempDir()
+		for _, name := range []string{"b.txt", "a.txt"} {
+			err := os.WriteFile(path.Join(testDir, name), []byte("hello"), 0600)
+			if err != nil {
+				panic(err)
+			}
+		}
+
+		err := os.Mkdir(path.Join(testDir, "sub"), 0700)
+		if err != nil {
+			panic(err)
+		}
+
+		fs := NewFilesys()
+		names, err := fs.List(testDir)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"a.txt", "b.txt"}, names)
+	})
+}
+
+func TestOsFilesys_Remove(t *testing.T) {
+	t.Run("Given Empty Path", func(t *testing.T) {
+		fs := NewFilesys()
+		err := fs.Remove("")
+		assert.Equal(t, ErrPathEmpty, err)
+	})
+
+	t.Run("Given Valid Path", func(t *testing.T) {
+		testPath := path.Join(t.TempDir(), "file.txt")
+		err := os.WriteFile(testPath, []byte("hello"), 0600)
+		if err != nil {
+			panic(err)
+		}
+
+		fs := NewFilesys()
+		err = fs.Remove(testPath)
+		assert.NoError(t, err)
+
+		_, err = os.Stat(testPath)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestOsFilesys_Lock(t *testing.T) {
+	t.Run("Given Empty Path", func(t *testing.T) {
+		fs := NewFilesys()
+		unlock, err := fs.Lock("")
+		assert.Nil(t, unlock)
+		assert.Equal(t, ErrPathEmpty, err)
+	})
+
+	t.Run("Where Directory Does Not Exist", func(t *testing.T) {
+		fs := NewFilesys()
+		unlock, err := fs.Lock("TestOsFilesys_Lock1/file.lock")
+		assert.Nil(t, unlock)
+		assert.Equal(t, ErrDirNotExists, err)
+	})
+
+	t.Run("Blocks Until Unlocked", func(t *testing.T) {
+		testPath := path.Join(t.TempDir(), "file.lock")
+
+		fs := NewFilesys()
+		unlock, err := fs.Lock(testPath)
+		assert.NoError(t, err)
+
+		locked := make(chan struct{})
+		go func() {
+			unlock, err := fs.Lock(testPath)
+			assert.NoError(t, err)
+			close(locked)
+			unlock()
+		}()
+
+		select {
+		case <-locked:
+			t.Fatal("lock was acquired while held")
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		assert.NoError(t, unlock())
+
+		select {
+		case <-locked:
+		case <-time.After(5 * time.Second):
+			t.Fatal("lock was not acquired after being released")
+		}
+	})
+}
@@ -0,0 +1,222 @@
+package passport
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/sethvargo/go-diceware/diceware"
+)
+
+// Kinds of secret which can be generated.
+const (
+	GenerateChars   = "chars"
+	GenerateWords   = "words"
+	GenerateHex     = "hex"
+	GenerateBase64  = "base64"
+	GenerateUUID    = "uuid"
+	GenerateRSA     = "rsa"
+	GenerateEd25519 = "ed25519"
+)
+
+// Default lengths of generated secrets, by kind, where the length is the
+// number of characters, words, random bytes or RSA key bits.
+var defaultGenerateLengths = map[string]int{
+	GenerateChars:  32,
+	GenerateWords:  6,
+	GenerateHex:    32,
+	GenerateBase64: 32,
+	GenerateRSA:    4096,
+}
+
+const minRSABits = 2048
+
+// charsets are the named sets of characters secrets can be generated from.
+var charsets = map[string]string{
+	"alnum":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789",
+	"alpha":   "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz",
+	"lower":   "abcdefghijklmnopqrstuvwxyz",
+	"upper":   "ABCDEFGHIJKLMNOPQRSTUVWXYZ",
+	"numeric": "0123456789",
+	"symbols": "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!#$%&()*+,-./:;<=>?@[]^_{|}~",
+}
+
+// Common generate errors.
+var (
+	ErrGenerateKindInvalid    = errors.New("generate: kind must be one of chars, words, hex, base64, uuid, rsa or ed25519")
+	ErrGenerateLengthInvalid  = errors.New("generate: length must be greater than zero")
+	ErrGenerateCharsetInvalid = errors.New("generate: charset must be alnum, alpha, lower, upper, numeric, symbols, or at least two characters")
+)
+
+// GenerateOptions determine the kind of secret generated.
+type GenerateOptions struct {
+	// Kind is the kind of secret, which defaults to chars.
+	Kind string
+	// Length is the number of characters, words, random bytes, for hex
+	// and base64, or bits, for RSA keys. If zero, a default is used.
+	Length int
+	// Charset is the name of the set of characters to use, or the
+	// characters themselves. Defaults to alnum.
+	Charset string
+	// Separator is used to join words. Defaults to "-".
+	Separator string
+}
+
+// GeneratedSecret is a generated secret value. For key pairs, the value is
+// the PEM encoded private key, and PublicKey is the PEM encoded public key.
+type GeneratedSecret struct {
+	Value     string
+	PublicKey string
+}
+
+// GenerateSecret generates a cryptographically random secret, as
+// determined by opts.
+func GenerateSecret(opts GenerateOptions) (*GeneratedSecret, error) {
+	kind := opts.Kind
+	if kind == "" {
+		kind = GenerateChars
+	}
+
+	length := opts.Length
+	if length == 0 {
+		length = defaultGenerateLengths[kind]
+	}
+
+	if length < 0 {
+		return nil, ErrGenerateLengthInvalid
+	}
+
+	switch kind {
+	case GenerateChars:
+		v, err := generateChars(length, opts.Charset)
+		if err != nil {
+			return nil, err
+		}
+
+		return &GeneratedSecret{Value: v}, nil
+	case GenerateWords:
+		words, err := diceware.Generate(length)
+		if err != nil {
+			return nil, err
+		}
+
+		sep := opts.Separator
+		if sep == "" {
+			sep = "-"
+		}
+
+		return &GeneratedSecret{Value: strings.Join(words, sep)}, nil
+	case GenerateHex, GenerateBase64:
+		b, err := randomBytes(length)
+		if err != nil {
+			return nil, err
+		}
+
+		if kind == GenerateHex {
+			return &GeneratedSecret{Value: hex.EncodeToString(b)}, nil
+		}
+
+		return &GeneratedSecret{Value: base64.StdEncoding.EncodeToString(b)}, nil
+	case GenerateUUID:
+		b, err := randomBytes(16)
+		if err != nil {
+			return nil, err
+		}
+
+		// set the version (4) and variant (RFC 4122) bits.
+		b[6] = (b[6] & 0x0f) | 0x40
+		b[8] = (b[8] & 0x3f) | 0x80
+
+		return &GeneratedSecret{
+			Value: fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]),
+		}, nil
+	case GenerateRSA:
+		if length < minRSABits {
+			return nil, fmt.Errorf("generate: rsa keys must be at least %d bits", minRSABits)
+		}
+
+		key, err := rsa.GenerateKey(rand.Reader, length)
+		if err != nil {
+			return nil, err
+		}
+
+		return encodeKeyPair(key, &key.PublicKey)
+	case GenerateEd25519:
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return encodeKeyPair(key, pub)
+	default:
+		return nil, ErrGenerateKindInvalid
+	}
+}
+
+// generateChars returns a random string of the given length, using the
+// characters of the named charset, or the characters given by charset.
+func generateChars(length int, charset string) (string, error) {
+	if charset == "" {
+		charset = "alnum"
+	}
+
+	chars, ok := charsets[charset]
+	if !ok {
+		chars = charset
+	}
+
+	runes := []rune(chars)
+	if len(runes) < 2 {
+		return "", ErrGenerateCharsetInvalid
+	}
+
+	max := big.NewInt(int64(len(runes)))
+	v := make([]rune, length)
+	for i := range v {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+
+		v[i] = runes[n.Int64()]
+	}
+
+	return string(v), nil
+}
+
+func randomBytes(n int) ([]byte, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// encodeKeyPair PEM encodes the private key, as PKCS #8, and the
+// public key, as PKIX.
+func encodeKeyPair(key, pub interface{}) (*GeneratedSecret, error) {
+	keyBytes, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	pubBytes, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GeneratedSecret{
+		Value:     string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyBytes})),
+		PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubBytes})),
+	}, nil
+}
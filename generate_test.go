@@ -0,0 +1,119 @@
+package passport
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	t.Run("Given Default Options", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{})
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[A-Za-z0-9]{32}$`, s.Value)
+		assert.Empty(t, s.PublicKey)
+	})
+
+	t.Run("Given Named Charset", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Length: 64, Charset: "numeric"})
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[0-9]{64}$`, s.Value)
+	})
+
+	t.Run("Given Custom Charset", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Length: 16, Charset: "ab£"})
+		assert.NoError(t, err)
+		assert.Regexp(t, `^[ab£]{16}$`, s.Value)
+	})
+
+	t.Run("Given Invalid Charset", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Charset: "a"})
+		assert.Nil(t, s)
+		assert.Equal(t, ErrGenerateCharsetInvalid, err)
+	})
+
+	t.Run("Values Are Random", func(t *testing.T) {
+		a, _ := GenerateSecret(GenerateOptions{})
+		b, _ := GenerateSecret(GenerateOptions{})
+		assert.NotEqual(t, a.Value, b.Value)
+	})
+
+	t.Run("Given Words", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateWords, Length: 4, Separator: " "})
+		assert.NoError(t, err)
+		assert.Equal(t, 4, len(strings.Split(s.Value, " ")))
+	})
+
+	t.Run("Given Hex", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateHex, Length: 16})
+		assert.NoError(t, err)
+
+		b, err := hex.DecodeString(s.Value)
+		assert.NoError(t, err)
+		assert.Equal(t, 16, len(b))
+	})
+
+	t.Run("Given Base64", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateBase64})
+		assert.NoError(t, err)
+
+		b, err := base64.StdEncoding.DecodeString(s.Value)
+		assert.NoError(t, err)
+		assert.Equal(t, 32, len(b))
+	})
+
+	t.Run("Given UUID", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateUUID})
+		assert.NoError(t, err)
+		assert.True(t, regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(s.Value))
+	})
+
+	t.Run("Given Ed25519", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateEd25519})
+		assert.NoError(t, err)
+
+		block, _ := pem.Decode([]byte(s.Value))
+		assert.Equal(t, "PRIVATE KEY", block.Type)
+		_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		assert.NoError(t, err)
+
+		block, _ = pem.Decode([]byte(s.PublicKey))
+		assert.Equal(t, "PUBLIC KEY", block.Type)
+		_, err = x509.ParsePKIXPublicKey(block.Bytes)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Given RSA", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateRSA, Length: 2048})
+		assert.NoError(t, err)
+
+		block, _ := pem.Decode([]byte(s.Value))
+		_, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		assert.NoError(t, err)
+		assert.Contains(t, s.PublicKey, "PUBLIC KEY")
+	})
+
+	t.Run("Given Small RSA Key", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: GenerateRSA, Length: 1024})
+		assert.Nil(t, s)
+		assert.EqualError(t, err, "generate: rsa keys must be at least 2048 bits")
+	})
+
+	t.Run("Given Invalid Kind", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Kind: "emoji"})
+		assert.Nil(t, s)
+		assert.Equal(t, ErrGenerateKindInvalid, err)
+	})
+
+	t.Run("Given Negative Length", func(t *testing.T) {
+		s, err := GenerateSecret(GenerateOptions{Length: -1})
+		assert.Nil(t, s)
+		assert.Equal(t, ErrGenerateLengthInvalid, err)
+	})
+}
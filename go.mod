@@ -1,12 +1,20 @@
 module github.com/reecerussell/passport
 
-go 1.16
+go 1.19
 
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
+	filippo.io/age v1.2.1
 	github.com/denisbrodbeck/machineid v1.0.1
 	github.com/golang/mock v1.5.0
+	github.com/sethvargo/go-diceware v0.2.1
 	github.com/stretchr/testify v1.7.0
-	golang.org/x/sys v0.0.0-20210603125802-9665404d3644 // indirect
+	golang.org/x/sys v0.21.0
+	golang.org/x/term v0.21.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.24.0 // indirect
+)
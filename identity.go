@@ -0,0 +1,159 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"gopkg.in/yaml.v3"
+)
+
+const identityFilename = "identity.yaml"
+
+// Common identity errors.
+var (
+	ErrIdentityInvalid  = errors.New("identity: invalid key")
+	ErrRecipientInvalid = errors.New("identity: public key must be an age public key, i.e. age1...")
+)
+
+// Identity is the user's X25519 keypair, in the format used by age, which
+// others use to share secrets with them. The private key is encrypted at
+// rest, using a CryptoProvider.
+type Identity struct {
+	PublicKey  string `yaml:"public_key"`
+	PrivateKey string `yaml:"private_key"`
+
+	x25519 *age.X25519Identity
+}
+
+// LoadIdentity reads the identity kept in configDir. If there isn't one,
+// a new keypair is generated and saved.
+func LoadIdentity(configDir string, fs Filesys, cp CryptoProvider) (*Identity, error) {
+	filePath := path.Join(configDir, identityFilename)
+	ok, err := fs.FileExists(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return newIdentity(filePath, fs, cp)
+	}
+
+	data, err := fs.Read(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	id := &Identity{}
+	err = yaml.Unmarshal(data, id)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIdentityInvalid, err.Error())
+	}
+
+	key, err := cp.DecryptString(id.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	id.x25519, err = age.ParseX25519Identity(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrIdentityInvalid, err.Error())
+	}
+
+	if id.x25519.Recipient().String() != id.PublicKey {
+		return nil, fmt.Errorf("%w: public key does not match private key", ErrIdentityInvalid)
+	}
+
+	return id, nil
+}
+
+// newIdentity generates a new identity, and saves it to filePath.
+func newIdentity(filePath string, fs Filesys, cp CryptoProvider) (*Identity, error) {
+	err := fs.EnsureDirectory(path.Dir(filePath))
+	if err != nil {
+		return nil, err
+	}
+
+	x, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := cp.EncryptString(x.String())
+	if err != nil {
+		return nil, err
+	}
+
+	id := &Identity{
+		PublicKey:  x.Recipient().String(),
+		PrivateKey: key,
+		x25519:     x,
+	}
+
+	data, _ := yaml.Marshal(id)
+	err = fs.Write(filePath, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
+// parseRecipients parses the given age public keys.
+func parseRecipients(keys []string) ([]age.Recipient, error) {
+	recipients := make([]age.Recipient, 0, len(keys))
+	for _, k := range keys {
+		r, err := age.ParseX25519Recipient(strings.TrimSpace(k))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrRecipientInvalid, k)
+		}
+
+		recipients = append(recipients, r)
+	}
+
+	return recipients, nil
+}
+
+// encryptArmored encrypts data to the recipients, in the ASCII armored
+// age format, which can be copied and pasted as text.
+func encryptArmored(data []byte, recipients ...age.Recipient) ([]byte, error) {
+	var buf bytes.Buffer
+	aw := armor.NewWriter(&buf)
+	w, err := age.Encrypt(aw, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = w.Write(data)
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err == nil {
+		err = aw.Close()
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// decryptArmored decrypts data, in the ASCII armored age format, using
+// any of the identities. Whitespace around data is ignored.
+func decryptArmored(data []byte, identities ...age.Identity) ([]byte, error) {
+	var r io.Reader = armor.NewReader(bytes.NewReader(bytes.TrimSpace(data)))
+	r, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.ReadAll(r)
+}
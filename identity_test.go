@@ -0,0 +1,124 @@
+package passport
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestLoadIdentity(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const testDir = "/config"
+	testFilePath := path.Join(testDir, identityFilename)
+
+	x, _ := age.GenerateX25519Identity()
+	testData, _ := yaml.Marshal(&Identity{
+		PublicKey:  x.Recipient().String(),
+		PrivateKey: "encrypted",
+	})
+
+	t.Run("Where Identity Exists", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(true, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return(x.String(), nil)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, x.Recipient().String(), id.PublicKey)
+		assert.Equal(t, x.String(), id.x25519.String())
+	})
+
+	t.Run("Where Identity Does Not Exist", func(t *testing.T) {
+		var saved []byte
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(false, nil)
+		fs.EXPECT().EnsureDirectory(testDir).Return(nil)
+		fs.EXPECT().Write(testFilePath, gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+			saved = data
+			return nil
+		})
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString(gomock.Any()).Return("encrypted", nil)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, id.x25519.Recipient().String(), id.PublicKey)
+		assert.Equal(t, "encrypted", id.PrivateKey)
+
+		var stored Identity
+		yaml.Unmarshal(saved, &stored)
+		assert.Equal(t, id.PublicKey, stored.PublicKey)
+		assert.Equal(t, "encrypted", stored.PrivateKey)
+	})
+
+	t.Run("Where Public Key Does Not Match", func(t *testing.T) {
+		other, _ := age.GenerateX25519Identity()
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(true, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return(other.String(), nil)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.Nil(t, id)
+		assert.True(t, errors.Is(err, ErrIdentityInvalid))
+	})
+
+	t.Run("Where Private Key Is Invalid", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(true, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return("invalid", nil)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.Nil(t, id)
+		assert.True(t, errors.Is(err, ErrIdentityInvalid))
+	})
+
+	t.Run("Where Decrypt Fails", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(true, nil)
+		fs.EXPECT().Read(testFilePath).Return(testData, nil)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return("", ErrDecryptFailed)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.Nil(t, id)
+		assert.Equal(t, ErrDecryptFailed, err)
+	})
+
+	t.Run("Where Write Fails", func(t *testing.T) {
+		testErr := errors.New("an error occurred")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(testFilePath).Return(false, nil)
+		fs.EXPECT().EnsureDirectory(testDir).Return(nil)
+		fs.EXPECT().Write(testFilePath, gomock.Any()).Return(testErr)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString(gomock.Any()).Return("encrypted", nil)
+
+		id, err := LoadIdentity(testDir, fs, cp)
+		assert.Nil(t, id)
+		assert.Equal(t, testErr, err)
+	})
+}
@@ -0,0 +1,196 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigVersion is the current version of the config file's schema. When
+// a config file with an older version is loaded, it is migrated to this
+// version, one migration at a time.
+const ConfigVersion = 1
+
+// ErrConfigVersionUnsupported is returned when a config file has a newer
+// version than this build of passport supports.
+var ErrConfigVersionUnsupported = errors.New("config: version is not supported, passport may need to be updated")
+
+// migration upgrades a config document to the given version, from
+// the version before it. Documents are migrated as YAML nodes, so that
+// older schemas don't have to be supported by Config, and the order of
+// the keys, and any comments, in the user's file are kept.
+type migration struct {
+	version     int
+	description string
+	migrate     func(doc *yaml.Node) error
+}
+
+// migrations is the registry of config migrations, ordered by version.
+// When a migration is added, ConfigVersion must be incremented.
+var migrations = []*migration{
+	{
+		version:     1,
+		description: "escape literal \"<<\" and fix <secret.NAME> references in commands",
+		migrate:     migrateV1,
+	},
+}
+
+// migrate upgrades the config document, data, read from filePath, to
+// ConfigVersion. If the document is migrated, a backup of the original
+// file is written to the config directory and the migrated document is
+// returned, along with true. Otherwise, data is returned as is. If the
+// config is encrypted, so is the backup. An existing backup of the same
+// version isn't replaced, so the new one is named by the time instead.
+// The configs of other stores are read-only, so are migrated in memory,
+// without a backup.
+func (c *Config) migrate(filePath string, data []byte) ([]byte, bool, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, false, fmt.Errorf("config: failed to parse %s: %w", filePath, err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+		doc = yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("config: failed to parse %s: expected a mapping", filePath)
+	}
+
+	version := 0
+	if v := mappingValue(root, "version"); v != nil {
+		err = v.Decode(&version)
+		if err != nil {
+			return nil, false, fmt.Errorf("config: version must be an integer, got %s", v.Value)
+		}
+	}
+
+	switch {
+	case version == ConfigVersion:
+		return data, false, nil
+	case version > ConfigVersion || version < 0:
+		return nil, false, ErrConfigVersionUnsupported
+	}
+
+	if !c.readOnly {
+		err = c.writeMigrationBackup(data, version)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+
+		err = m.migrate(root)
+		if err != nil {
+			return nil, false, fmt.Errorf("config: failed to migrate to version %d: %w", m.version, err)
+		}
+
+		setVersion(root, m.version)
+	}
+
+	migrated, _ := yaml.Marshal(&doc)
+
+	return migrated, true, nil
+}
+
+// writeMigrationBackup writes data, the config before it was migrated from
+// version, to the config directory, without replacing an earlier backup.
+func (c *Config) writeMigrationBackup(data []byte, version int) error {
+	backup, err := c.encode(data)
+	if err != nil {
+		return err
+	}
+
+	backupPath := path.Join(c.configDir, fmt.Sprintf("%s.v%d.bak", configFilename, version))
+	exists, err := c.fs.FileExists(backupPath)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		id := now().UTC().Format(backupTimeFormat)
+		backupPath = path.Join(c.configDir, fmt.Sprintf("%s.v%d-%s.bak", configFilename, version, id))
+	}
+
+	return c.fs.Write(backupPath, backup)
+}
+
+// mappingValue returns the value of key in the mapping node m,
+// or nil if m isn't a mapping, or doesn't contain key.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// setVersion sets the version of the config document, root,
+// adding it to the start of the document if it isn't set.
+func setVersion(root *yaml.Node, version int) {
+	v := mappingValue(root, "version")
+	if v == nil {
+		v = &yaml.Node{}
+		root.Content = append([]*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"}, v}, root.Content...)
+	}
+
+	*v = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(version)}
+}
+
+// v1ReferencePattern matches the sequences in commands which are
+// interpreted differently since commands became templates.
+var v1ReferencePattern = regexp.MustCompile(`<<|<secret\.`)
+
+// migrateV1 migrates a config document to version 1. Prior to version 1,
+// commands were not parsed as templates, so "<<" was literal text, and
+// <secret.NAME> references, shown in the README, were never resolved.
+// Literal "<<" is now escaped, and <secret.NAME> becomes <secrets.NAME>.
+func migrateV1(doc *yaml.Node) error {
+	workspaces := mappingValue(doc, "workspaces")
+	if workspaces == nil || workspaces.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	for _, w := range workspaces.Content {
+		scripts := mappingValue(w, "scripts")
+		if scripts == nil || scripts.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		for _, s := range scripts.Content {
+			command := mappingValue(s, "command")
+			if command == nil || command.Kind != yaml.ScalarNode {
+				continue
+			}
+
+			command.Value = v1ReferencePattern.ReplaceAllStringFunc(command.Value, func(m string) string {
+				if m == "<<" {
+					return "<<<<"
+				}
+
+				return "<secrets."
+			})
+		}
+	}
+
+	return nil
+}
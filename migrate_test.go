@@ -0,0 +1,161 @@
+package passport
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestMigrations(t *testing.T) {
+	assert.Equal(t, ConfigVersion, len(migrations))
+
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version)
+		assert.NotEmpty(t, m.description)
+		assert.NotNil(t, m.migrate)
+	}
+}
+
+func TestMigrateV1(t *testing.T) {
+	data := `# my config
+workspaces:
+    - scripts:
+        - command: docker build --build-arg "pass=<secret.MySecret>" <secrets.Other>
+          name: build
+        - name: heredoc
+          command: cat <<EOF <<secret.literal>
+        - name: no-command
+      name: app
+    - name: empty
+`
+
+	var doc yaml.Node
+	assert.NoError(t, yaml.Unmarshal([]byte(data), &doc))
+
+	err := migrateV1(doc.Content[0])
+	assert.NoError(t, err)
+
+	out, _ := yaml.Marshal(&doc)
+	assert.Equal(t, `# my config
+workspaces:
+    - scripts:
+        - command: docker build --build-arg "pass=<secrets.MySecret>" <secrets.Other>
+          name: build
+        - name: heredoc
+          command: cat <<<<EOF <<<<secret.literal>
+        - name: no-command
+      name: app
+    - name: empty
+`, string(out))
+}
+
+func TestLoadConfig_Migrate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testDir := ".config"
+	testFilePath := path.Join(testDir, configFilename)
+
+	t.Run("Where Config Is Unversioned", func(t *testing.T) {
+		testData := `secrets: []
+workspaces:
+- name: app
+  path: /c/app
+  scripts:
+  - name: build
+    command: echo <secret.MySecret>`
+
+		fs := mock.NewMockFilesys(ctrl)
+		gomock.InOrder(
+			fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil),
+			fs.EXPECT().FileExists(path.Join(testDir, "config.yaml.v0.bak")).Return(false, nil),
+			fs.EXPECT().Write(path.Join(testDir, "config.yaml.v0.bak"), []byte(testData)).Return(nil),
+			fs.EXPECT().Write(testFilePath, gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+				assert.Contains(t, string(data), "version: 1\n")
+				assert.Contains(t, string(data), "command: echo <secrets.MySecret>\n")
+				return nil
+			}),
+		)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, ConfigVersion, c.Version)
+		assert.Equal(t, "echo <secrets.MySecret>", c.Workspaces[0].Scripts[0].Command)
+	})
+
+	t.Run("Keeps Order Of Keys", func(t *testing.T) {
+		testData := "# workspaces first\nworkspaces: []\nsecrets: []\n"
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(testData), nil)
+		fs.EXPECT().FileExists(path.Join(testDir, "config.yaml.v0.bak")).Return(false, nil)
+		fs.EXPECT().Write(path.Join(testDir, "config.yaml.v0.bak"), []byte(testData)).Return(nil)
+		fs.EXPECT().Write(testFilePath, []byte("version: 1\n# workspaces first\nworkspaces: []\nsecrets: []\n")).Return(nil)
+
+		_, err := LoadConfig(testDir, fs, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Backup Exists", func(t *testing.T) {
+		setNow(t, time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC))
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte("secrets: []"), nil)
+		fs.EXPECT().FileExists(path.Join(testDir, "config.yaml.v0.bak")).Return(true, nil)
+		fs.EXPECT().Write(path.Join(testDir, "config.yaml.v0-20210501-123015.000.bak"), []byte("secrets: []")).Return(nil)
+		fs.EXPECT().Write(testFilePath, gomock.Any()).Return(nil)
+
+		_, err := LoadConfig(testDir, fs, nil)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Config Is Empty", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte(""), nil)
+		fs.EXPECT().FileExists(path.Join(testDir, "config.yaml.v0.bak")).Return(false, nil)
+		fs.EXPECT().Write(path.Join(testDir, "config.yaml.v0.bak"), []byte("")).Return(nil)
+		fs.EXPECT().Write(testFilePath, gomock.Any()).Return(nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, ConfigVersion, c.Version)
+	})
+
+	t.Run("Where Backup Fails", func(t *testing.T) {
+		testErr := errors.New("fs: error")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte("secrets: []"), nil)
+		fs.EXPECT().FileExists(path.Join(testDir, "config.yaml.v0.bak")).Return(false, nil)
+		fs.EXPECT().Write(path.Join(testDir, "config.yaml.v0.bak"), gomock.Any()).Return(testErr)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("Where Version Is Newer", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte("version: 1000\nsecrets: []"), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.Equal(t, ErrConfigVersionUnsupported, err)
+	})
+
+	t.Run("Where Version Is Not An Integer", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().Read(testFilePath).Return([]byte("version: one\nsecrets: []"), nil)
+
+		c, err := LoadConfig(testDir, fs, nil)
+		assert.Nil(t, c)
+		assert.EqualError(t, err, "config: version must be an integer, got one")
+	})
+}
@@ -62,6 +62,36 @@ func (mr *MockFilesysMockRecorder) FileExists(path interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FileExists", reflect.TypeOf((*MockFilesys)(nil).FileExists), path)
 }
 
+// List mocks base method.
+func (m *MockFilesys) List(path string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", path)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockFilesysMockRecorder) List(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockFilesys)(nil).List), path)
+}
+
+// Lock mocks base method.
+func (m *MockFilesys) Lock(path string) (func() error, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Lock", path)
+	ret0, _ := ret[0].(func() error)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Lock indicates an expected call of Lock.
+func (mr *MockFilesysMockRecorder) Lock(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Lock", reflect.TypeOf((*MockFilesys)(nil).Lock), path)
+}
+
 // Read mocks base method.
 func (m *MockFilesys) Read(path string) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -77,6 +107,20 @@ func (mr *MockFilesysMockRecorder) Read(path interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Read", reflect.TypeOf((*MockFilesys)(nil).Read), path)
 }
 
+// Remove mocks base method.
+func (m *MockFilesys) Remove(path string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Remove", path)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Remove indicates an expected call of Remove.
+func (mr *MockFilesysMockRecorder) Remove(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Remove", reflect.TypeOf((*MockFilesys)(nil).Remove), path)
+}
+
 // Write mocks base method.
 func (m *MockFilesys) Write(path string, data []byte) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,181 @@
+package passport
+
+import (
+	"errors"
+	"strings"
+)
+
+// Common profile errors.
+var (
+	ErrProfileNameEmpty  = errors.New("profile: name is empty")
+	ErrProfileNameExists = errors.New("profile: name already exists")
+	ErrProfileNotFound   = errors.New("profile: not found")
+)
+
+// ProfileEnvVar is the name of the environment variable which can
+// be used to select a profile, when one is not given explicitly.
+const ProfileEnvVar = "PASSPORT_PROFILE"
+
+// Profile is a named set of variables and secret mappings, which allows
+// the same scripts to be run against different environments, i.e. dev,
+// staging and prod. Profiles can be defined globally, or on a workspace.
+type Profile struct {
+	Name string `yaml:"name"`
+
+	// Vars maps variable names to values, used to resolve <vars.X> references.
+	Vars VarSet `yaml:"vars,omitempty"`
+
+	// Secrets maps the secret names referenced in commands, to the name
+	// of the stored secret which should be used in their place.
+	Secrets map[string]string `yaml:"secrets,omitempty"`
+}
+
+// SetVar sets the value of the variable, name, on the profile.
+func (p *Profile) SetVar(name, value string) error {
+	if name == "" {
+		return ErrVarNameEmpty
+	}
+
+	p.Vars.set(name, value)
+
+	return nil
+}
+
+// MapSecret maps references to the secret, ref, to the stored secret
+// with the given name, when the profile is active.
+func (p *Profile) MapSecret(ref, name string) error {
+	if ref == "" || name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	if p.Secrets == nil {
+		p.Secrets = make(map[string]string)
+	}
+
+	p.Secrets[ref] = name
+
+	return nil
+}
+
+// SecretName returns the name of the stored secret which should be
+// used for references to ref. If ref is not mapped, it is returned as is.
+func (p *Profile) SecretName(ref string) string {
+	if name, ok := p.Secrets[ref]; ok {
+		return name
+	}
+
+	return ref
+}
+
+// ProfileSet is a wrapper around []*Profile, which provides helper functions.
+type ProfileSet []*Profile
+
+// Add adds a new, empty profile with the given name to the set.
+func (set *ProfileSet) Add(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return ErrProfileNameEmpty
+	}
+
+	if _, err := set.Get(name); err == nil {
+		return ErrProfileNameExists
+	}
+
+	*set = append(*set, &Profile{Name: name})
+
+	return nil
+}
+
+// Get returns the profile in the set with the given name. If the
+// profile does not exist, ErrProfileNotFound is returned.
+func (set ProfileSet) Get(name string) (*Profile, error) {
+	if name == "" {
+		return nil, ErrProfileNameEmpty
+	}
+
+	for _, p := range set {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return nil, ErrProfileNotFound
+}
+
+// Remove removes the profile with the given name from the set. If
+// the profile does not exist, ErrProfileNotFound is returned.
+func (set *ProfileSet) Remove(name string) error {
+	if name == "" {
+		return ErrProfileNameEmpty
+	}
+
+	for i, p := range *set {
+		if p.Name == name {
+			*set = append((*set)[:i], (*set)[i+1:]...)
+
+			return nil
+		}
+	}
+
+	return ErrProfileNotFound
+}
+
+// UseProfile sets the active profile, used to resolve variables and
+// secrets when running scripts. An empty name deactivates profiles.
+func (c *Config) UseProfile(name string) {
+	c.profile = name
+}
+
+// Profile returns the name of the active profile.
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// activeProfile returns the active profile for the script, merged from
+// the global config of each store and the script's workspace, where the
+// workspace's definitions take precedence, followed by the stores in
+// order. If no profile is active, an empty profile is returned.
+func (s *WorkspaceScript) activeProfile() (*Profile, error) {
+	p := &Profile{
+		Vars:    make(VarSet),
+		Secrets: make(map[string]string),
+	}
+
+	if s.c == nil || s.c.profile == "" {
+		return p, nil
+	}
+
+	p.Name = s.c.profile
+	stack := s.c.stack()
+	sets := make([]ProfileSet, 0, len(stack)+1)
+	for i := len(stack) - 1; i >= 0; i-- {
+		sets = append(sets, stack[i].Profiles)
+	}
+
+	if s.w != nil {
+		sets = append(sets, s.w.Profiles)
+	}
+
+	found := false
+	for _, set := range sets {
+		sp, err := set.Get(p.Name)
+		if err != nil {
+			continue
+		}
+
+		found = true
+		for k, v := range sp.Vars {
+			p.Vars[k] = v
+		}
+
+		for k, v := range sp.Secrets {
+			p.Secrets[k] = v
+		}
+	}
+
+	if !found {
+		return nil, ErrProfileNotFound
+	}
+
+	return p, nil
+}
@@ -0,0 +1,224 @@
+package passport
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestProfileSet_Add(t *testing.T) {
+	set := ProfileSet{
+		{
+			Name: "dev",
+		},
+	}
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		err := set.Add("staging")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(set))
+		assert.Equal(t, "staging", set[1].Name)
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := set.Add(" ")
+		assert.Equal(t, ErrProfileNameEmpty, err)
+	})
+
+	t.Run("Where Name Already Exists", func(t *testing.T) {
+		err := set.Add("dev")
+		assert.Equal(t, ErrProfileNameExists, err)
+	})
+}
+
+func TestProfileSet_Get(t *testing.T) {
+	set := ProfileSet{
+		{
+			Name: "dev",
+		},
+	}
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		p, err := set.Get("dev")
+		assert.NoError(t, err)
+		assert.Equal(t, set[0], p)
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		p, err := set.Get("")
+		assert.Nil(t, p)
+		assert.Equal(t, ErrProfileNameEmpty, err)
+	})
+
+	t.Run("Where Profile Does Not Exist", func(t *testing.T) {
+		p, err := set.Get("prod")
+		assert.Nil(t, p)
+		assert.Equal(t, ErrProfileNotFound, err)
+	})
+}
+
+func TestProfileSet_Remove(t *testing.T) {
+	set := ProfileSet{
+		{
+			Name: "dev",
+		},
+	}
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := set.Remove("")
+		assert.Equal(t, ErrProfileNameEmpty, err)
+	})
+
+	t.Run("Where Profile Does Not Exist", func(t *testing.T) {
+		err := set.Remove("prod")
+		assert.Equal(t, ErrProfileNotFound, err)
+	})
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		err := set.Remove("dev")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(set))
+	})
+}
+
+func TestProfile_SetVar(t *testing.T) {
+	p := &Profile{}
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		err := p.SetVar("REGION", "eu-west-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", p.Vars["REGION"])
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := p.SetVar("", "eu-west-1")
+		assert.Equal(t, ErrVarNameEmpty, err)
+	})
+}
+
+func TestProfile_MapSecret(t *testing.T) {
+	p := &Profile{}
+
+	t.Run("Given Valid Names", func(t *testing.T) {
+		err := p.MapSecret("DB_PASSWORD", "STAGING_DB_PASSWORD")
+		assert.NoError(t, err)
+		assert.Equal(t, "STAGING_DB_PASSWORD", p.SecretName("DB_PASSWORD"))
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := p.MapSecret("DB_PASSWORD", "")
+		assert.Equal(t, ErrSecretNameEmpty, err)
+	})
+
+	t.Run("Where Secret Is Not Mapped", func(t *testing.T) {
+		assert.Equal(t, "API_KEY", p.SecretName("API_KEY"))
+	})
+}
+
+func TestWorkspaceScript_Run_WithProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cnf := &Config{
+		Secrets: []*Secret{
+			{
+				Name:  "dev-password",
+				Value: "dev123",
+			},
+			{
+				Name:  "staging-password",
+				Value: "staging123",
+			},
+		},
+		Profiles: ProfileSet{
+			{
+				Name:    "staging",
+				Vars:    map[string]string{"region": "eu-west-1", "host": "global"},
+				Secrets: map[string]string{"password": "staging-password"},
+			},
+		},
+	}
+	w := &Workspace{
+		c: cnf,
+		Profiles: ProfileSet{
+			{
+				Name: "staging",
+				Vars: map[string]string{"host": "staging.local"},
+			},
+		},
+	}
+
+	newScript := func(command string) *WorkspaceScript {
+		if os.Getenv("GOOS") != "linux" {
+			command = "cmd /C " + command
+		}
+
+		return &WorkspaceScript{c: cnf, w: w, Command: command}
+	}
+
+	t.Run("Given Active Profile", func(t *testing.T) {
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			panic(err)
+		}
+
+		oldStdout := os.Stdout
+		os.Stdout = pw
+
+		t.Cleanup(func() {
+			pw.Close()
+			os.Stdout = oldStdout
+		})
+
+		cnf.UseProfile("staging")
+		defer cnf.UseProfile("")
+
+		s := newScript("echo <vars.host> <vars.region> <secrets.password>")
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+
+		pw.Close()
+		os.Stdout = oldStdout
+
+		bytes, _ := ioutil.ReadAll(pr)
+		assert.Contains(t, string(bytes), "staging.local eu-west-1 staging123")
+	})
+
+	t.Run("Where Profile Does Not Exist", func(t *testing.T) {
+		cnf.UseProfile("prod")
+		defer cnf.UseProfile("")
+
+		s := newScript("echo <vars.region>")
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.Equal(t, ErrProfileNotFound, err)
+		assert.Equal(t, -1, code)
+	})
+
+	t.Run("Where Var Does Not Exist", func(t *testing.T) {
+		s := newScript("echo <vars.region>")
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.ErrorIs(t, err, ErrVarNotFound)
+		assert.Equal(t, -1, code)
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		s := newScript("echo <secrets.password>")
+		cp := mock.NewMockCryptoProvider(ctrl)
+
+		code, err := s.Run(cp)
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+		assert.Equal(t, -1, code)
+	})
+}
@@ -0,0 +1,135 @@
+package passport
+
+import (
+	"bufio"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Common prompt errors.
+var (
+	// ErrSecretValueMismatch is returned when a value entered at a prompt
+	// does not match its confirmation.
+	ErrSecretValueMismatch = errors.New("secret: values do not match")
+
+	// ErrPassphraseRequired is returned when a passphrase is needed, but
+	// can't be read from a prompt.
+	ErrPassphraseRequired = errors.New("age: a passphrase is required, but stdin is not a terminal")
+
+	// ErrConfirmRequired is returned when an action must be confirmed,
+	// but it can't be asked at a prompt.
+	ErrConfirmRequired = errors.New("prompt: confirmation is required, but stdin is not a terminal")
+)
+
+// isTerminal returns true if f is a terminal, and is replaced in tests.
+var isTerminal = func(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// readPassword reads a line from the terminal, f, without echoing
+// it, and is replaced in tests.
+var readPassword = func(f *os.File) ([]byte, error) {
+	return term.ReadPassword(int(f.Fd()))
+}
+
+// readLine reads a line from the terminal, f, and is replaced in tests.
+var readLine = func(f *os.File) (string, error) {
+	line, err := bufio.NewReader(f).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// ReadSecretValue reads a secret's value from in, so it isn't passed as an
+// argument, where it would be seen in shell history. If in is a terminal,
+// the value is read from a prompt, written to out, without being echoed,
+// then confirmed. Otherwise, all of in is read, allowing multi-line values,
+// where a single trailing new line is removed. If encode is true, the value
+// read is base64 encoded, as is, allowing binary values.
+func ReadSecretValue(in *os.File, out io.Writer, encode bool) (string, error) {
+	if !isTerminal(in) {
+		data, err := ioutil.ReadAll(in)
+		if err != nil {
+			return "", err
+		}
+
+		if encode {
+			return base64.StdEncoding.EncodeToString(data), nil
+		}
+
+		v := strings.TrimSuffix(string(data), "\n")
+		return strings.TrimSuffix(v, "\r"), nil
+	}
+
+	fmt.Fprint(out, "Value: ")
+	v, err := readPassword(in)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+
+	fmt.Fprint(out, "Confirm Value: ")
+	confirm, err := readPassword(in)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+
+	if string(v) != string(confirm) {
+		return "", ErrSecretValueMismatch
+	}
+
+	if encode {
+		return base64.StdEncoding.EncodeToString(v), nil
+	}
+
+	return string(v), nil
+}
+
+// ReadPassphrase reads a passphrase from a prompt, written to out, without
+// echoing it. If in is not a terminal, ErrPassphraseRequired is returned.
+func ReadPassphrase(in *os.File, out io.Writer) (string, error) {
+	if !isTerminal(in) {
+		return "", ErrPassphraseRequired
+	}
+
+	fmt.Fprint(out, "Passphrase: ")
+	v, err := readPassword(in)
+	fmt.Fprintln(out)
+	if err != nil {
+		return "", err
+	}
+
+	return string(v), nil
+}
+
+// confirm asks question at a prompt, written to out, returning true if
+// the answer, read from in, is yes. If in is not a terminal,
+// ErrConfirmRequired is returned.
+func confirm(in *os.File, out io.Writer, question string) (bool, error) {
+	if !isTerminal(in) {
+		return false, ErrConfirmRequired
+	}
+
+	fmt.Fprintf(out, "%s [y/N]: ", question)
+	v, err := readLine(in)
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
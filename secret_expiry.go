@@ -0,0 +1,153 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultExpiryWarning is how long before a secret expires that warnings
+// are printed, when the config does not specify an expiry_warning.
+const DefaultExpiryWarning = 7 * 24 * time.Hour
+
+// Common secret expiry errors.
+var (
+	ErrSecretExpired        = errors.New("secret: expired")
+	ErrSecretRotateInvalid  = errors.New("secret: rotate_every is not a valid duration")
+	ErrExpiryWarningInvalid = errors.New("config: expiry_warning is not a valid duration")
+	ErrDurationInvalid      = errors.New("duration: must be a number followed by a unit, i.e. 12h, 14d or 2w")
+)
+
+// warnOutput is where warnings are written, and is replaced in tests.
+var warnOutput io.Writer = os.Stderr
+
+// ParseDuration parses a duration, which, in addition to the units
+// supported by time.ParseDuration, can be given in days or weeks,
+// i.e. 14d or 2w.
+func ParseDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if !strings.HasSuffix(s, suffix) {
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+		if err != nil || n < 0 {
+			return 0, ErrDurationInvalid
+		}
+
+		return time.Duration(n) * unit, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, ErrDurationInvalid
+	}
+
+	return d, nil
+}
+
+// Expiry returns the time the secret expires, which is the earliest of
+// its ExpiresAt and, if set, the time it's due to be rotated. If the
+// secret does not expire, false is returned.
+func (s *Secret) Expiry() (time.Time, bool) {
+	expiry := s.ExpiresAt
+	if s.RotateEvery != "" && !s.UpdatedAt.IsZero() {
+		d, err := ParseDuration(s.RotateEvery)
+		if err == nil {
+			rotateAt := s.UpdatedAt.Add(d)
+			if expiry.IsZero() || rotateAt.Before(expiry) {
+				expiry = rotateAt
+			}
+		}
+	}
+
+	return expiry, !expiry.IsZero()
+}
+
+// IsExpiring returns true if the secret expires within d, or has expired.
+func (s *Secret) IsExpiring(d time.Duration) bool {
+	expiry, ok := s.Expiry()
+
+	return ok && !now().Add(d).Before(expiry)
+}
+
+// SetSecretExpiry sets the time the secret with the given name expires,
+// and how often it should be rotated. A zero expiresAt, or empty
+// rotateEvery, removes them.
+func (c *Config) SetSecretExpiry(name string, expiresAt time.Time, rotateEvery string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	if rotateEvery != "" {
+		if _, err := ParseDuration(rotateEvery); err != nil {
+			return ErrSecretRotateInvalid
+		}
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	secret.ExpiresAt = expiresAt
+	secret.RotateEvery = rotateEvery
+	if rotateEvery != "" && secret.UpdatedAt.IsZero() {
+		secret.UpdatedAt = now().UTC().Truncate(time.Second)
+	}
+
+	return store.Put(secret)
+}
+
+// expiryWarning returns how long before a secret expires that
+// warnings are printed.
+func (c *Config) expiryWarning() time.Duration {
+	if c.ExpiryWarning == "" {
+		return DefaultExpiryWarning
+	}
+
+	d, err := ParseDuration(c.ExpiryWarning)
+	if err != nil {
+		return DefaultExpiryWarning
+	}
+
+	return d
+}
+
+// checkExpiry prints a warning if the secret has expired, or will expire
+// soon. If the secret has expired and the config's FailOnExpired is set,
+// ErrSecretExpired is returned instead. Warnings are printed once per secret.
+func (c *Config) checkExpiry(s *Secret) error {
+	expiry, ok := s.Expiry()
+	if !ok || !s.IsExpiring(c.expiryWarning()) {
+		return nil
+	}
+
+	expired := !now().Before(expiry)
+	if expired && c.FailOnExpired {
+		return fmt.Errorf("%w: %s expired on %s", ErrSecretExpired, s.Name, expiry.Local().Format("2006-01-02"))
+	}
+
+	if c.warned == nil {
+		c.warned = make(map[string]bool)
+	}
+
+	if c.warned[s.Name] {
+		return nil
+	}
+
+	c.warned[s.Name] = true
+
+	if expired {
+		fmt.Fprintf(warnOutput, "warning: secret %s expired on %s, and should be rotated\n", s.Name, expiry.Local().Format("2006-01-02"))
+	} else {
+		fmt.Fprintf(warnOutput, "warning: secret %s expires on %s\n", s.Name, expiry.Local().Format("2006-01-02"))
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setWarnOutput(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	oldWarnOutput := warnOutput
+	warnOutput = &buf
+
+	t.Cleanup(func() {
+		warnOutput = oldWarnOutput
+	})
+
+	return &buf
+}
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		in  string
+		out time.Duration
+		err error
+	}{
+		{"14d", 14 * 24 * time.Hour, nil},
+		{"2w", 14 * 24 * time.Hour, nil},
+		{"12h", 12 * time.Hour, nil},
+		{"1h30m", 90 * time.Minute, nil},
+		{"", 0, ErrDurationInvalid},
+		{"d", 0, ErrDurationInvalid},
+		{"-1d", 0, ErrDurationInvalid},
+		{"soon", 0, ErrDurationInvalid},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			d, err := ParseDuration(tt.in)
+			assert.Equal(t, tt.out, d)
+			assert.Equal(t, tt.err, err)
+		})
+	}
+}
+
+func TestSecret_Expiry(t *testing.T) {
+	updatedAt := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	expiresAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Where Secret Does Not Expire", func(t *testing.T) {
+		s := &Secret{UpdatedAt: updatedAt}
+		_, ok := s.Expiry()
+		assert.False(t, ok)
+	})
+
+	t.Run("Given ExpiresAt", func(t *testing.T) {
+		s := &Secret{UpdatedAt: updatedAt, ExpiresAt: expiresAt}
+		expiry, ok := s.Expiry()
+		assert.True(t, ok)
+		assert.Equal(t, expiresAt, expiry)
+	})
+
+	t.Run("Given RotateEvery", func(t *testing.T) {
+		s := &Secret{UpdatedAt: updatedAt, RotateEvery: "14d"}
+		expiry, ok := s.Expiry()
+		assert.True(t, ok)
+		assert.Equal(t, updatedAt.Add(14*24*time.Hour), expiry)
+	})
+
+	t.Run("Uses Earliest Of ExpiresAt And RotateEvery", func(t *testing.T) {
+		s := &Secret{UpdatedAt: updatedAt, ExpiresAt: expiresAt, RotateEvery: "90d"}
+		expiry, _ := s.Expiry()
+		assert.Equal(t, expiresAt, expiry)
+
+		s.RotateEvery = "7d"
+		expiry, _ = s.Expiry()
+		assert.Equal(t, updatedAt.Add(7*24*time.Hour), expiry)
+	})
+
+	t.Run("IsExpiring", func(t *testing.T) {
+		setNow(t, time.Date(2021, 5, 20, 0, 0, 0, 0, time.UTC))
+
+		s := &Secret{ExpiresAt: expiresAt}
+		assert.False(t, s.IsExpiring(7*24*time.Hour))
+		assert.True(t, s.IsExpiring(14*24*time.Hour))
+
+		s = &Secret{ExpiresAt: updatedAt}
+		assert.True(t, s.IsExpiring(0))
+
+		s = &Secret{}
+		assert.False(t, s.IsExpiring(365*24*time.Hour))
+	})
+}
+
+func TestConfig_SetSecretExpiry(t *testing.T) {
+	testNow := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	setNow(t, testNow)
+
+	expiresAt := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Given Valid Expiry", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+		err := c.SetSecretExpiry("Token", expiresAt, "90d")
+		assert.NoError(t, err)
+		assert.Equal(t, expiresAt, c.Secrets[0].ExpiresAt)
+		assert.Equal(t, "90d", c.Secrets[0].RotateEvery)
+		assert.Equal(t, testNow, c.Secrets[0].UpdatedAt)
+	})
+
+	t.Run("Given Invalid RotateEvery", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+		err := c.SetSecretExpiry("Token", expiresAt, "often")
+		assert.Equal(t, ErrSecretRotateInvalid, err)
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		c := &Config{}
+		err := c.SetSecretExpiry("Token", expiresAt, "")
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+
+	t.Run("Where Secret Is Updated", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc", ExpiresAt: expiresAt}}}
+		err := c.SetSecret("Token", "def", false, nil)
+		assert.NoError(t, err)
+		assert.True(t, c.Secrets[0].ExpiresAt.IsZero())
+	})
+}
+
+func TestWorkspaceScript_commandArgs_Expiry(t *testing.T) {
+	setNow(t, time.Date(2021, 5, 30, 0, 0, 0, 0, time.UTC))
+
+	newScript := func(c *Config) *WorkspaceScript {
+		c.Secrets = []*Secret{
+			{Name: "Expired", Value: "a", ExpiresAt: time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)},
+			{Name: "Expiring", Value: "b", ExpiresAt: time.Date(2021, 6, 2, 0, 0, 0, 0, time.UTC)},
+			{Name: "Valid", Value: "c", ExpiresAt: time.Date(2021, 9, 1, 0, 0, 0, 0, time.UTC)},
+		}
+		c.Workspaces = []*Workspace{
+			{
+				Name: "app",
+				Path: "/c/app",
+				Scripts: []*WorkspaceScript{
+					{Name: "build", Command: "echo <secrets.Expired> <secrets.Expired> <secrets.Expiring> <secrets.Valid>"},
+				},
+			},
+		}
+
+		w, _ := c.GetWorkspace("/c/app")
+		s, _ := w.GetScript("build")
+
+		return s
+	}
+
+	t.Run("Prints Warnings Once", func(t *testing.T) {
+		buf := setWarnOutput(t)
+
+		s := newScript(&Config{})
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"echo", "a", "a", "b", "c"}, args)
+
+		out := buf.String()
+		assert.Equal(t, 1, bytes.Count(buf.Bytes(), []byte("secret Expired expired")))
+		assert.Contains(t, out, "secret Expiring expires")
+		assert.NotContains(t, out, "Valid")
+	})
+
+	t.Run("Given Expiry Warning", func(t *testing.T) {
+		buf := setWarnOutput(t)
+
+		s := newScript(&Config{ExpiryWarning: "1d"})
+		_, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.NotContains(t, buf.String(), "Expiring")
+	})
+
+	t.Run("Where FailOnExpired Is Set", func(t *testing.T) {
+		setWarnOutput(t)
+
+		s := newScript(&Config{FailOnExpired: true})
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrSecretExpired))
+		assert.Contains(t, err.Error(), "Expired")
+	})
+}
@@ -0,0 +1,228 @@
+package passport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Formats secrets can be exported to, in addition to FormatDotenv and
+// FormatJSON. FormatShell and FormatPowerShell write statements which set
+// environment variables, when evaluated by a POSIX shell or PowerShell.
+const (
+	FormatShell      = "shell"
+	FormatPowerShell = "powershell"
+)
+
+// Common export errors.
+var (
+	ErrExportFormatInvalid = errors.New("export: format must be one of dotenv, json, shell or powershell")
+	ErrExportNameInvalid   = errors.New("export: name is not a valid environment variable name")
+)
+
+var (
+	// envName matches names which can be used as environment variables in a shell.
+	envName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+	// dotenvSafe matches dotenv values which don't need to be quoted.
+	dotenvSafe = regexp.MustCompile(`^[A-Za-z0-9_./:@+,\-]*$`)
+
+	// powerShellQuotes escapes the characters PowerShell reads as
+	// single quotes, which includes the typographic quotes.
+	powerShellQuotes = strings.NewReplacer(
+		"'", "''",
+		"\u2018", "\u2018\u2018",
+		"\u2019", "\u2019\u2019",
+		"\u201A", "\u201A\u201A",
+		"\u201B", "\u201B\u201B",
+	)
+)
+
+// ExportSecrets returns the plain-text values of the secrets whose names
+// match any of the given patterns, sorted by name. Patterns use the syntax
+// of path.Match, i.e. "APP_*". If no patterns are given, all secrets
+// are returned. If workspace secrets are used, the workspace's secrets are
+// returned, along with any global secrets they don't replace. If any of the
+// secrets are restricted by a policy, none are exported, unless force is
+// set, and see CheckSecretRead.
+func (c *Config) ExportSecrets(patterns []string, force bool, cp CryptoProvider) ([]*SecretValue, error) {
+	var secrets []*Secret
+	if c.secretScope != "" {
+		list, err := c.SecretStore().List()
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, list...)
+	}
+
+	global, err := c.ListSecrets()
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(secrets))
+	for _, s := range secrets {
+		seen[s.Name] = true
+	}
+
+	for _, s := range global {
+		if !seen[s.Name] {
+			secrets = append(secrets, s)
+		}
+	}
+
+	var matched []*Secret
+	var restricted []string
+	for _, s := range secrets {
+		ok, err := matchAny(patterns, s.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			continue
+		}
+
+		matched = append(matched, s)
+		if s.Restricted() {
+			restricted = append(restricted, s.Name)
+		}
+	}
+
+	if len(restricted) > 0 && !force {
+		sort.Strings(restricted)
+		return nil, fmt.Errorf("%w: %s", ErrSecretRestricted, strings.Join(restricted, ", "))
+	}
+
+	values := make([]*SecretValue, 0, len(matched))
+	for _, s := range matched {
+		err := c.CheckSecretRead(s, force)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := s.GetValue(cp)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, s.Name)
+		}
+
+		values = append(values, &SecretValue{Name: s.Name, Value: v})
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Name < values[j].Name
+	})
+
+	return values, nil
+}
+
+// matchAny determines whether name matches any of the patterns,
+// or if there are no patterns.
+func matchAny(patterns []string, name string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+
+	for _, p := range patterns {
+		ok, err := path.Match(p, name)
+		if err != nil {
+			return false, fmt.Errorf("%w: %s", err, p)
+		}
+
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// FormatSecrets writes the values to w, in the given format. Values are
+// escaped, or quoted, so they're read back exactly as they are.
+func FormatSecrets(w io.Writer, values []*SecretValue, format string) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, values)
+	case FormatDotenv, FormatShell, FormatPowerShell:
+		return writeLines(w, values, format)
+	default:
+		return ErrExportFormatInvalid
+	}
+}
+
+// writeJSON writes the values to w as an object, indented by two spaces.
+func writeJSON(w io.Writer, values []*SecretValue) error {
+	doc := make(map[string]string, len(values))
+	for _, v := range values {
+		doc[v.Name] = v.Value
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(doc)
+}
+
+// writeLines writes a line to w for each of the values, in the given format.
+// Nothing is written if any of the names are invalid.
+func writeLines(w io.Writer, values []*SecretValue, format string) error {
+	var b strings.Builder
+	for _, v := range values {
+		line, err := formatLine(v, format)
+		if err != nil {
+			return err
+		}
+
+		b.WriteString(line + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// formatLine returns the line which sets v, in the given format.
+func formatLine(v *SecretValue, format string) (string, error) {
+	if format == FormatDotenv {
+		if !dotenvKey.MatchString(v.Name) {
+			return "", fmt.Errorf("%w: %s", ErrExportNameInvalid, v.Name)
+		}
+
+		return v.Name + "=" + quoteDotenv(v.Value), nil
+	}
+
+	if !envName.MatchString(v.Name) {
+		return "", fmt.Errorf("%w: %s", ErrExportNameInvalid, v.Name)
+	}
+
+	if format == FormatPowerShell {
+		return "$env:" + v.Name + " = '" + powerShellQuotes.Replace(v.Value) + "'", nil
+	}
+
+	return "export " + v.Name + "='" + strings.ReplaceAll(v.Value, "'", `'\''`) + "'", nil
+}
+
+// quoteDotenv double quotes the value, if needed,
+// escaping the characters read by parseDotenv.
+func quoteDotenv(s string) string {
+	if dotenvSafe.MatchString(s) {
+		return s
+	}
+
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"$", `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,205 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestConfig_ExportSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "APP_TOKEN", Value: "encrypted", Secure: true},
+			{Name: "APP_KEY", Value: "key"},
+			{Name: "DB_PASSWORD", Value: "password"},
+		},
+	}
+
+	t.Run("Given No Patterns", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return("token", nil)
+
+		values, err := c.ExportSecrets(nil, false, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "APP_KEY", Value: "key"},
+			{Name: "APP_TOKEN", Value: "token"},
+			{Name: "DB_PASSWORD", Value: "password"},
+		}, values)
+	})
+
+	t.Run("Given Patterns", func(t *testing.T) {
+		values, err := c.ExportSecrets([]string{"APP_K*", "DB_*"}, false, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "APP_KEY", Value: "key"},
+			{Name: "DB_PASSWORD", Value: "password"},
+		}, values)
+	})
+
+	t.Run("Given Invalid Pattern", func(t *testing.T) {
+		values, err := c.ExportSecrets([]string{"APP_["}, false, nil)
+		assert.Nil(t, values)
+		assert.True(t, errors.Is(err, path.ErrBadPattern))
+	})
+
+	t.Run("Where Decrypt Fails", func(t *testing.T) {
+		testErr := errors.New("an error occurred")
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().DecryptString("encrypted").Return("", testErr)
+
+		values, err := c.ExportSecrets([]string{"APP_TOKEN"}, false, cp)
+		assert.Nil(t, values)
+		assert.True(t, errors.Is(err, testErr))
+	})
+
+	t.Run("Where Secrets Are Restricted", func(t *testing.T) {
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "APP_KEY", Value: "key"},
+				{Name: "DEPLOY_KEY", Value: "deploy", Allow: []*SecretAccess{{Workspace: "app"}}},
+				{Name: "DB_PASSWORD", Value: "password", Confirm: true},
+			},
+		}
+
+		values, err := c.ExportSecrets(nil, false, nil)
+		assert.Nil(t, values)
+		assert.True(t, errors.Is(err, ErrSecretRestricted))
+		assert.Contains(t, err.Error(), ": DB_PASSWORD, DEPLOY_KEY")
+
+		values, err = c.ExportSecrets([]string{"APP_*"}, false, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(values))
+	})
+
+	t.Run("Given Force", func(t *testing.T) {
+		setConfirmOutput(t)
+		setTerminalInput(t, "y")
+
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "DEPLOY_KEY", Value: "deploy", Allow: []*SecretAccess{{Workspace: "app"}}},
+				{Name: "DB_PASSWORD", Value: "password", Confirm: true},
+			},
+		}
+
+		values, err := c.ExportSecrets(nil, true, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "DB_PASSWORD", Value: "password"},
+			{Name: "DEPLOY_KEY", Value: "deploy"},
+		}, values)
+	})
+}
+
+func TestConfig_ExportSecrets_Workspace(t *testing.T) {
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "APP_KEY", Value: "global key"},
+			{Name: "DB_PASSWORD", Value: "password"},
+		},
+		Workspaces: []*Workspace{
+			{
+				Name: "app",
+				Path: "/c/app",
+				Secrets: []*Secret{
+					{Name: "APP_KEY", Value: "workspace key"},
+					{Name: "APP_URL", Value: "url"},
+				},
+			},
+		},
+	}
+
+	c.UseWorkspaceSecrets("/c/app")
+
+	values, err := c.ExportSecrets(nil, false, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []*SecretValue{
+		{Name: "APP_KEY", Value: "workspace key"},
+		{Name: "APP_URL", Value: "url"},
+		{Name: "DB_PASSWORD", Value: "password"},
+	}, values)
+}
+
+func TestFormatSecrets(t *testing.T) {
+	values := []*SecretValue{
+		{Name: "PLAIN", Value: "abc-123"},
+		{Name: "QUOTED", Value: "it's \"$HOME\"\nnext \\ line"},
+	}
+
+	t.Run("Given Dotenv", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, values, FormatDotenv)
+		assert.NoError(t, err)
+		assert.Equal(t, "PLAIN=abc-123\n"+
+			`QUOTED="it's \"\$HOME\"\nnext \\ line"`+"\n", buf.String())
+
+		parsed, err := ParseSecrets(buf.Bytes(), FormatDotenv)
+		assert.NoError(t, err)
+		assert.Equal(t, values, parsed)
+	})
+
+	t.Run("Given JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, values, FormatJSON)
+		assert.NoError(t, err)
+		assert.Equal(t, "{\n"+
+			`  "PLAIN": "abc-123",`+"\n"+
+			`  "QUOTED": "it's \"$HOME\"\nnext \\ line"`+"\n"+
+			"}\n", buf.String())
+
+		parsed, err := ParseSecrets(buf.Bytes(), FormatJSON)
+		assert.NoError(t, err)
+		assert.Equal(t, values, parsed)
+	})
+
+	t.Run("Given Shell", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, values, FormatShell)
+		assert.NoError(t, err)
+		assert.Equal(t, "export PLAIN='abc-123'\n"+
+			`export QUOTED='it'\''s "$HOME"`+"\n"+`next \ line'`+"\n", buf.String())
+	})
+
+	t.Run("Given PowerShell", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, values, FormatPowerShell)
+		assert.NoError(t, err)
+		assert.Equal(t, "$env:PLAIN = 'abc-123'\n"+
+			`$env:QUOTED = 'it''s "$HOME"`+"\n"+`next \ line'`+"\n", buf.String())
+	})
+
+	t.Run("Given PowerShell With Typographic Quotes", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, []*SecretValue{
+			{Name: "QUOTED", Value: "a\u2018b\u2019c\u201Ad\u201Be\u2019; calc; \u2019"},
+		}, FormatPowerShell)
+		assert.NoError(t, err)
+		assert.Equal(t, "$env:QUOTED = 'a\u2018\u2018b\u2019\u2019c\u201A\u201Ad\u201B\u201Be\u2019\u2019; calc; \u2019\u2019'\n", buf.String())
+	})
+
+	t.Run("Given Invalid Name", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := FormatSecrets(&buf, []*SecretValue{
+			{Name: "PLAIN", Value: "abc"},
+			{Name: "my-secret", Value: "abc"},
+		}, FormatShell)
+		assert.True(t, errors.Is(err, ErrExportNameInvalid))
+		assert.Equal(t, "", buf.String())
+	})
+
+	t.Run("Given Invalid Format", func(t *testing.T) {
+		err := FormatSecrets(&bytes.Buffer{}, values, "toml")
+		assert.Equal(t, ErrExportFormatInvalid, err)
+	})
+}
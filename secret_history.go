@@ -0,0 +1,140 @@
+package passport
+
+import (
+	"errors"
+	"time"
+)
+
+// Common secret version errors.
+var (
+	ErrSecretVersionNotFound = errors.New("secret: version not found")
+	ErrSecretVersionCurrent  = errors.New("secret: version is already current")
+)
+
+// SecretVersion is a version of a secret's value, which is kept in the
+// secret's history when the secret is updated.
+type SecretVersion struct {
+	Version   int       `yaml:"version"`
+	Value     string    `yaml:"value,omitempty"`
+	Secure    bool      `yaml:"secure"`
+	Source    string    `yaml:"source,omitempty"`
+	Timeout   string    `yaml:"timeout,omitempty"`
+	CreatedAt time.Time `yaml:"created_at,omitempty"`
+}
+
+// currentVersion returns the version number of the secret's current
+// value. Secrets created before versions were kept are version 1.
+func (s *Secret) currentVersion() int {
+	if s.Version < 1 {
+		return 1
+	}
+
+	return s.Version
+}
+
+// Versions returns each version of the secret, oldest first,
+// where the last is the secret's current value.
+func (s *Secret) Versions() []*SecretVersion {
+	versions := make([]*SecretVersion, 0, len(s.History)+1)
+	versions = append(versions, s.History...)
+
+	return append(versions, &SecretVersion{
+		Version:   s.currentVersion(),
+		Value:     s.Value,
+		Secure:    s.Secure,
+		Source:    s.Source,
+		Timeout:   s.Timeout,
+		CreatedAt: s.UpdatedAt,
+	})
+}
+
+// update replaces the secret's value with v, as a new version,
+// keeping the current value in the secret's history. As the value
+// has changed, the secret's ExpiresAt is cleared.
+func (s *Secret) update(v *SecretVersion) {
+	current := s.Versions()
+	s.History = current
+
+	s.Version = current[len(current)-1].Version + 1
+	s.Value = v.Value
+	s.Secure = v.Secure
+	s.Source = v.Source
+	s.Timeout = v.Timeout
+	s.UpdatedAt = now().UTC().Truncate(time.Second)
+	s.ExpiresAt = time.Time{}
+
+	s.resolved = false
+	s.resolvedValue = ""
+}
+
+// SetSecret sets the value of the secret with the given name, in the
+// config's SecretStore. If the secret exists, its value is updated, and
+// the previous value is kept in its history, unless the store versions
+// secrets itself, such as Vault. Otherwise, the secret is added. If the
+// encrypt flag is true, the value will be encrypted.
+func (c *Config) SetSecret(name, value string, encrypt bool, cp CryptoProvider) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	if value == "" {
+		return ErrSecretValueEmpty
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return c.AddSecret(name, value, encrypt, cp)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if encrypt {
+		value, err = cp.EncryptString(value)
+		if err != nil {
+			return err
+		}
+	}
+
+	secret.update(&SecretVersion{
+		Value:  value,
+		Secure: encrypt,
+	})
+
+	if !keepsHistory(store) {
+		secret.History = nil
+	}
+
+	return store.Put(secret)
+}
+
+// RollbackSecret sets the value of the secret with the given name back to
+// the given version. The rollback is added as a new version, so the
+// history of the secret is kept.
+func (c *Config) RollbackSecret(name string, version int) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	if version == secret.currentVersion() {
+		return ErrSecretVersionCurrent
+	}
+
+	for _, v := range secret.History {
+		if v.Version == version {
+			secret.update(v)
+
+			return store.Put(secret)
+		}
+	}
+
+	return ErrSecretVersionNotFound
+}
@@ -0,0 +1,159 @@
+package passport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestConfig_SetSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t1 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		setNow(t, t1)
+
+		c := &Config{}
+		err := c.SetSecret("MySecret", "one", false, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Secret{
+			{Name: "MySecret", Value: "one", Version: 1, UpdatedAt: t1},
+		}, c.Secrets)
+	})
+
+	t.Run("Where Secret Exists", func(t *testing.T) {
+		setNow(t, t2)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString("two").Return("encrypted", nil)
+
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "MySecret", Value: "one", Version: 1, UpdatedAt: t1},
+			},
+		}
+		err := c.SetSecret("MySecret", "two", true, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, []*Secret{
+			{
+				Name:      "MySecret",
+				Value:     "encrypted",
+				Secure:    true,
+				Version:   2,
+				UpdatedAt: t2,
+				History: []*SecretVersion{
+					{Version: 1, Value: "one", CreatedAt: t1},
+				},
+			},
+		}, c.Secrets)
+	})
+
+	t.Run("Where Secret Has No Version", func(t *testing.T) {
+		setNow(t, t2)
+
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "MySecret", Source: "file:~/token"},
+			},
+		}
+		err := c.SetSecret("MySecret", "two", false, nil)
+		assert.NoError(t, err)
+
+		s := c.Secrets[0]
+		assert.Equal(t, 2, s.Version)
+		assert.Equal(t, "two", s.Value)
+		assert.Equal(t, "", s.Source)
+		assert.Equal(t, []*SecretVersion{{Version: 1, Source: "file:~/token"}}, s.History)
+	})
+
+	t.Run("Given Empty Value", func(t *testing.T) {
+		c := &Config{}
+		err := c.SetSecret("MySecret", "", false, nil)
+		assert.Equal(t, ErrSecretValueEmpty, err)
+	})
+
+	t.Run("Where Encrypt Fails", func(t *testing.T) {
+		testErr := errors.New("crypto: error")
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString("two").Return("", testErr)
+
+		c := &Config{
+			Secrets: []*Secret{{Name: "MySecret", Value: "one"}},
+		}
+		err := c.SetSecret("MySecret", "two", true, cp)
+		assert.Equal(t, testErr, err)
+		assert.Equal(t, "one", c.Secrets[0].Value)
+	})
+}
+
+func TestConfig_RollbackSecret(t *testing.T) {
+	t1 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+	t3 := time.Date(2021, 5, 3, 12, 0, 0, 0, time.UTC)
+
+	newConfig := func() *Config {
+		return &Config{
+			Secrets: []*Secret{
+				{
+					Name:      "MySecret",
+					Value:     "two",
+					Version:   2,
+					UpdatedAt: t2,
+					History: []*SecretVersion{
+						{Version: 1, Value: "encrypted", Secure: true, CreatedAt: t1},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("Given Previous Version", func(t *testing.T) {
+		setNow(t, t3)
+
+		c := newConfig()
+		err := c.RollbackSecret("MySecret", 1)
+		assert.NoError(t, err)
+
+		s := c.Secrets[0]
+		assert.Equal(t, "encrypted", s.Value)
+		assert.True(t, s.Secure)
+		assert.Equal(t, 3, s.Version)
+		assert.Equal(t, t3, s.UpdatedAt)
+		assert.Equal(t, []*SecretVersion{
+			{Version: 1, Value: "encrypted", Secure: true, CreatedAt: t1},
+			{Version: 2, Value: "two", CreatedAt: t2},
+		}, s.History)
+	})
+
+	t.Run("Given Current Version", func(t *testing.T) {
+		c := newConfig()
+		err := c.RollbackSecret("MySecret", 2)
+		assert.Equal(t, ErrSecretVersionCurrent, err)
+	})
+
+	t.Run("Given Unknown Version", func(t *testing.T) {
+		c := newConfig()
+		err := c.RollbackSecret("MySecret", 5)
+		assert.Equal(t, ErrSecretVersionNotFound, err)
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		c := newConfig()
+		err := c.RollbackSecret("Other", 1)
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+}
+
+func TestSecret_Versions(t *testing.T) {
+	s := &Secret{Name: "MySecret", Value: "one"}
+	assert.Equal(t, []*SecretVersion{{Version: 1, Value: "one"}}, s.Versions())
+}
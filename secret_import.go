@@ -0,0 +1,358 @@
+package passport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/reecerussell/passport/template"
+	"gopkg.in/yaml.v3"
+)
+
+// Formats secrets can be imported from.
+const (
+	FormatDotenv = "dotenv"
+	FormatJSON   = "json"
+	FormatYAML   = "yaml"
+)
+
+// Common import errors.
+var (
+	ErrImportFormatInvalid  = errors.New("import: format must be one of dotenv, json or yaml")
+	ErrImportSyntax         = errors.New("import: invalid syntax")
+	ErrImportValueInvalid   = errors.New("import: value must be a string, number or boolean")
+	ErrImportNameInvalid    = errors.New("import: names may only contain letters, digits, '-' and '_'")
+	ErrSecretImportConflict = errors.New("import: secrets already exist, use --overwrite to replace them")
+)
+
+// dotenvKey matches the names of variables in a dotenv file.
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+// SecretValue is the name and plain-text value of a secret,
+// as read from, or written to, a file.
+type SecretValue struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// ImportOptions determine how secrets are imported.
+type ImportOptions struct {
+	// Prefix is prepended to the name of each secret.
+	Prefix string
+
+	// Overwrite determines whether existing secrets are replaced,
+	// otherwise they're reported as conflicts.
+	Overwrite bool
+
+	// DryRun determines whether the result is only reported,
+	// without changing any secrets.
+	DryRun bool
+
+	// Encrypt determines whether values are encrypted.
+	Encrypt bool
+}
+
+// ImportResult lists the names of secrets changed, or
+// which would be changed, by an import.
+type ImportResult struct {
+	Added     []string
+	Updated   []string
+	Conflicts []string
+
+	// Skipped are secrets with empty values.
+	Skipped []string
+}
+
+// ParseSecrets reads secrets from data in the given format. Values in a JSON
+// or YAML file must be at the top level, where numbers and booleans are read
+// as strings. Where a name is given more than once, the last value is used.
+func ParseSecrets(data []byte, format string) ([]*SecretValue, error) {
+	switch format {
+	case FormatDotenv:
+		return parseDotenv(data)
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatYAML:
+		return parseYAML(data)
+	default:
+		return nil, ErrImportFormatInvalid
+	}
+}
+
+// parseDotenv parses KEY=VALUE lines, which may start with "export".
+// Single quoted values are taken literally, whereas double quoted values
+// support escapes, such as \n; both may span multiple lines. Comments
+// start with a #, at the start of a line, or after a space.
+func parseDotenv(data []byte) ([]*SecretValue, error) {
+	src := strings.ReplaceAll(string(data), "\r\n", "\n")
+	values := secretValues{}
+
+	line := 1
+	for len(src) > 0 {
+		var text string
+		text, src = cut(src, "\n")
+		start := line
+		line++
+
+		text = strings.TrimSpace(text)
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimPrefix(text, "export ")
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("%w: line %d: expected KEY=VALUE", ErrImportSyntax, start)
+		}
+
+		name := strings.TrimSpace(text[:i])
+		if !dotenvKey.MatchString(name) {
+			return nil, fmt.Errorf("%w: line %d: invalid name %q", ErrImportSyntax, start, name)
+		}
+
+		value := strings.TrimLeft(text[i+1:], " \t")
+		if value == "" || (value[0] != '"' && value[0] != '\'') {
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = value[:i]
+			}
+
+			values.set(name, strings.TrimSpace(value))
+			continue
+		}
+
+		// Quoted values may continue onto the following lines.
+		quote := value[0]
+		value = value[1:] + "\n" + src
+		end := closingQuote(value, quote)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: line %d: unterminated quote", ErrImportSyntax, start)
+		}
+
+		rest := value[end+1:]
+		value = value[:end]
+		line += strings.Count(value, "\n")
+
+		text, src = cut(rest, "\n")
+		text = strings.TrimSpace(text)
+		if text != "" && !strings.HasPrefix(text, "#") {
+			return nil, fmt.Errorf("%w: line %d: unexpected %q after quoted value", ErrImportSyntax, line-1, text)
+		}
+
+		if quote == '"' {
+			value = unescapeDotenv(value)
+		}
+
+		values.set(name, value)
+	}
+
+	return values, nil
+}
+
+// cut slices s around the first instance of sep, returning the text before
+// and after it. If sep doesn't appear in s, s and an empty string is returned.
+func cut(s, sep string) (string, string) {
+	if i := strings.Index(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):]
+	}
+
+	return s, ""
+}
+
+// closingQuote returns the index of the quote which closes s, skipping
+// escaped double quotes, or -1 if there isn't one.
+func closingQuote(s string, quote byte) int {
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quote == '"':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// unescapeDotenv replaces escape sequences in a double quoted dotenv value.
+// Unknown escapes are left as they are.
+func unescapeDotenv(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		case '"', '\\', '$':
+			b.WriteByte(s[i])
+		default:
+			b.WriteByte('\\')
+			b.WriteByte(s[i])
+		}
+	}
+
+	return b.String()
+}
+
+func parseJSON(data []byte) ([]*SecretValue, error) {
+	var doc map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	err := dec.Decode(&doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrImportSyntax, err.Error())
+	}
+
+	names := make([]string, 0, len(doc))
+	for name := range doc {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	values := make(secretValues, 0, len(names))
+	for _, name := range names {
+		switch v := doc[name].(type) {
+		case nil:
+			values.set(name, "")
+		case string:
+			values.set(name, v)
+		case json.Number:
+			values.set(name, v.String())
+		case bool:
+			values.set(name, fmt.Sprint(v))
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrImportValueInvalid, name)
+		}
+	}
+
+	return values, nil
+}
+
+func parseYAML(data []byte) ([]*SecretValue, error) {
+	var doc yaml.Node
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrImportSyntax, err.Error())
+	}
+
+	values := secretValues{}
+	if len(doc.Content) == 0 {
+		return values, nil
+	}
+
+	m := doc.Content[0]
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%w: line %d: expected a mapping", ErrImportSyntax, m.Line)
+	}
+
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if v.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("%w: %s", ErrImportValueInvalid, k.Value)
+		}
+
+		if v.Tag == "!!null" {
+			values.set(k.Value, "")
+			continue
+		}
+
+		values.set(k.Value, v.Value)
+	}
+
+	return values, nil
+}
+
+// secretValues is a list of SecretValues, in the order they were read.
+type secretValues []*SecretValue
+
+// set sets the value of the secret with the given name,
+// adding it if it hasn't been set before.
+func (values *secretValues) set(name, value string) {
+	for _, v := range *values {
+		if v.Name == name {
+			v.Value = value
+			return
+		}
+	}
+
+	*values = append(*values, &SecretValue{Name: name, Value: value})
+}
+
+// ImportSecrets adds the given secrets to the config's SecretStore, prefixing
+// their names with opts.Prefix. Secrets with empty values are skipped. If any
+// of the prefixed names can't be used in a template, none of the secrets are
+// imported, and ErrImportNameInvalid is returned, listing all of them. If any
+// of the secrets already exist, and opts.Overwrite isn't set, none of them are
+// imported, and ErrSecretImportConflict is returned, along with the result.
+// Overwritten secrets keep their previous value in their history.
+func (c *Config) ImportSecrets(values []*SecretValue, opts *ImportOptions, cp CryptoProvider) (*ImportResult, error) {
+	var invalid []string
+	for _, v := range values {
+		name := opts.Prefix + v.Name
+		if !template.ValidName(name) {
+			invalid = append(invalid, fmt.Sprintf("%q", name))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrImportNameInvalid, strings.Join(invalid, ", "))
+	}
+
+	store := c.SecretStore()
+	result := &ImportResult{}
+	var imports []*SecretValue
+
+	for _, v := range values {
+		name := opts.Prefix + v.Name
+		if v.Value == "" {
+			result.Skipped = append(result.Skipped, name)
+			continue
+		}
+
+		_, err := store.Get(name)
+		switch {
+		case err == nil && opts.Overwrite:
+			result.Updated = append(result.Updated, name)
+		case err == nil:
+			result.Conflicts = append(result.Conflicts, name)
+			continue
+		case errors.Is(err, ErrSecretNotFound):
+			result.Added = append(result.Added, name)
+		default:
+			return nil, err
+		}
+
+		imports = append(imports, &SecretValue{Name: name, Value: v.Value})
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+
+	if len(result.Conflicts) > 0 {
+		return result, fmt.Errorf("%w: %s", ErrSecretImportConflict, strings.Join(result.Conflicts, ", "))
+	}
+
+	for _, v := range imports {
+		err := c.SetSecret(v.Name, v.Value, opts.Encrypt, cp)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
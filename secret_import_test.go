@@ -0,0 +1,249 @@
+package passport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestParseSecrets(t *testing.T) {
+	t.Run("Given Dotenv", func(t *testing.T) {
+		data := []byte("# comment\r\n" +
+			"PLAIN=value\r\n" +
+			"export EXPORTED=exported\n" +
+			"SPACED = spaced value # comment\n" +
+			"HASH=abc#123\n" +
+			"\n" +
+			"SINGLE='$HOME'\n" +
+			"DOUBLE=\"say \\\"hi\\\"\\n\\tthere\" # comment\n" +
+			"MULTI=\"-----BEGIN KEY-----\n" +
+			"abc\n" +
+			"-----END KEY-----\"\n" +
+			"EMPTY=\n" +
+			"PLAIN=replaced")
+
+		values, err := ParseSecrets(data, FormatDotenv)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "PLAIN", Value: "replaced"},
+			{Name: "EXPORTED", Value: "exported"},
+			{Name: "SPACED", Value: "spaced value"},
+			{Name: "HASH", Value: "abc#123"},
+			{Name: "SINGLE", Value: "$HOME"},
+			{Name: "DOUBLE", Value: "say \"hi\"\n\tthere"},
+			{Name: "MULTI", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----"},
+			{Name: "EMPTY", Value: ""},
+		}, values)
+	})
+
+	t.Run("Given Dotenv With Literal Single Quotes", func(t *testing.T) {
+		values, err := ParseSecrets([]byte(`KEY='a \n "b" $c'`), FormatDotenv)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{{Name: "KEY", Value: `a \n "b" $c`}}, values)
+	})
+
+	t.Run("Given Dotenv Without Equals", func(t *testing.T) {
+		values, err := ParseSecrets([]byte("A=1\nINVALID\n"), FormatDotenv)
+		assert.Nil(t, values)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+		assert.Contains(t, err.Error(), "line 2")
+	})
+
+	t.Run("Given Dotenv With Invalid Name", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("MY KEY=1"), FormatDotenv)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+	})
+
+	t.Run("Given Dotenv With Unterminated Quote", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("A=1\nB=\"abc\n\ndef\n"), FormatDotenv)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+		assert.Contains(t, err.Error(), "line 2")
+	})
+
+	t.Run("Given Dotenv With Text After Quote", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("A=\"a\nb\" c\n"), FormatDotenv)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+		assert.Contains(t, err.Error(), "line 2")
+	})
+
+	t.Run("Given JSON", func(t *testing.T) {
+		data := []byte(`{"b": "two", "a": 1.50, "c": true, "d": null}`)
+
+		values, err := ParseSecrets(data, FormatJSON)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "a", Value: "1.50"},
+			{Name: "b", Value: "two"},
+			{Name: "c", Value: "true"},
+			{Name: "d", Value: ""},
+		}, values)
+	})
+
+	t.Run("Given JSON With Object Value", func(t *testing.T) {
+		_, err := ParseSecrets([]byte(`{"a": {"b": "c"}}`), FormatJSON)
+		assert.True(t, errors.Is(err, ErrImportValueInvalid))
+	})
+
+	t.Run("Given Invalid JSON", func(t *testing.T) {
+		_, err := ParseSecrets([]byte(`["a"]`), FormatJSON)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+	})
+
+	t.Run("Given YAML", func(t *testing.T) {
+		data := []byte("b: two\na: 0123\nc: |\n  multi\n  line\nd: ~\n")
+
+		values, err := ParseSecrets(data, FormatYAML)
+		assert.NoError(t, err)
+		assert.Equal(t, []*SecretValue{
+			{Name: "b", Value: "two"},
+			{Name: "a", Value: "0123"},
+			{Name: "c", Value: "multi\nline\n"},
+			{Name: "d", Value: ""},
+		}, values)
+	})
+
+	t.Run("Given YAML List", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("- a\n- b\n"), FormatYAML)
+		assert.True(t, errors.Is(err, ErrImportSyntax))
+	})
+
+	t.Run("Given YAML With List Value", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("a:\n  - b\n"), FormatYAML)
+		assert.True(t, errors.Is(err, ErrImportValueInvalid))
+	})
+
+	t.Run("Given Invalid Format", func(t *testing.T) {
+		_, err := ParseSecrets([]byte("A=1"), "toml")
+		assert.Equal(t, ErrImportFormatInvalid, err)
+	})
+}
+
+func TestConfig_ImportSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t1 := time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2021, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	values := []*SecretValue{
+		{Name: "A", Value: "one"},
+		{Name: "B", Value: "two"},
+		{Name: "C", Value: ""},
+	}
+
+	t.Run("Where Secrets Do Not Exist", func(t *testing.T) {
+		setNow(t, t2)
+
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString("one").Return("encrypted one", nil)
+		cp.EXPECT().EncryptString("two").Return("encrypted two", nil)
+
+		c := &Config{}
+		result, err := c.ImportSecrets(values, &ImportOptions{Prefix: "APP_", Encrypt: true}, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{
+			Added:   []string{"APP_A", "APP_B"},
+			Skipped: []string{"APP_C"},
+		}, result)
+		assert.Equal(t, []*Secret{
+			{Name: "APP_A", Value: "encrypted one", Secure: true, Version: 1, UpdatedAt: t2},
+			{Name: "APP_B", Value: "encrypted two", Secure: true, Version: 1, UpdatedAt: t2},
+		}, c.Secrets)
+	})
+
+	t.Run("Where Secrets Exist", func(t *testing.T) {
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "B", Value: "old", Version: 1, UpdatedAt: t1},
+			},
+		}
+		result, err := c.ImportSecrets(values, &ImportOptions{}, nil)
+		assert.True(t, errors.Is(err, ErrSecretImportConflict))
+		assert.Contains(t, err.Error(), "B")
+		assert.Equal(t, []string{"B"}, result.Conflicts)
+		assert.Len(t, c.Secrets, 1)
+	})
+
+	t.Run("Given Invalid Names", func(t *testing.T) {
+		c := &Config{}
+		result, err := c.ImportSecrets([]*SecretValue{
+			{Name: "A", Value: "one"},
+			{Name: "db.host", Value: "localhost"},
+			{Name: "", Value: "empty"},
+			{Name: "B C", Value: ""},
+		}, &ImportOptions{}, nil)
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, ErrImportNameInvalid)
+		assert.Contains(t, err.Error(), `"db.host", "", "B C"`)
+		assert.NotContains(t, err.Error(), `"A"`)
+		assert.Empty(t, c.Secrets)
+	})
+
+	t.Run("Given Invalid Prefix", func(t *testing.T) {
+		c := &Config{}
+		_, err := c.ImportSecrets(values, &ImportOptions{Prefix: "app."}, nil)
+		assert.ErrorIs(t, err, ErrImportNameInvalid)
+		assert.Empty(t, c.Secrets)
+	})
+
+	t.Run("Where Secrets Exist Given Overwrite", func(t *testing.T) {
+		setNow(t, t2)
+
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "B", Value: "old", Version: 1, UpdatedAt: t1},
+			},
+		}
+		result, err := c.ImportSecrets(values, &ImportOptions{Overwrite: true}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{
+			Added:   []string{"A"},
+			Updated: []string{"B"},
+			Skipped: []string{"C"},
+		}, result)
+		assert.Equal(t, []*Secret{
+			{
+				Name:      "B",
+				Value:     "two",
+				Version:   2,
+				UpdatedAt: t2,
+				History: []*SecretVersion{
+					{Version: 1, Value: "old", CreatedAt: t1},
+				},
+			},
+			{Name: "A", Value: "one", Version: 1, UpdatedAt: t2},
+		}, c.Secrets)
+	})
+
+	t.Run("Given Dry Run", func(t *testing.T) {
+		c := &Config{
+			Secrets: []*Secret{
+				{Name: "B", Value: "old"},
+			},
+		}
+		result, err := c.ImportSecrets(values, &ImportOptions{DryRun: true}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, &ImportResult{
+			Added:     []string{"A"},
+			Conflicts: []string{"B"},
+			Skipped:   []string{"C"},
+		}, result)
+		assert.Equal(t, []*Secret{{Name: "B", Value: "old"}}, c.Secrets)
+	})
+
+	t.Run("Where Store Fails", func(t *testing.T) {
+		testErr := errors.New("an error occurred")
+
+		c := &Config{}
+		c.SetSecretStore(&testSecretStore{err: testErr})
+
+		result, err := c.ImportSecrets(values, &ImportOptions{}, nil)
+		assert.Nil(t, result)
+		assert.Equal(t, testErr, err)
+	})
+}
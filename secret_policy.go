@@ -0,0 +1,199 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Common secret policy errors.
+var (
+	ErrSecretAccessDenied         = errors.New("secret: not allowed to be used here")
+	ErrSecretAccessNotFound       = errors.New("secret: access not found")
+	ErrSecretAccessWorkspaceEmpty = errors.New("secret: access must name a workspace")
+	ErrSecretNotConfirmed         = errors.New("secret: use was not confirmed")
+	ErrSecretRestricted           = errors.New("secret: restricted by a policy, use --force to read it")
+)
+
+// confirmInput and confirmOutput are where prompts to confirm the use
+// of secrets are read from, and written to, and are replaced in tests.
+var (
+	confirmInput  *os.File  = os.Stdin
+	confirmOutput io.Writer = os.Stderr
+)
+
+// SecretAccess allows a workspace, by name, to use a secret. If Script
+// is set, only that script of the workspace may use the secret.
+type SecretAccess struct {
+	Workspace string `yaml:"workspace"`
+	Script    string `yaml:"script,omitempty"`
+}
+
+// String returns the workspace, and script, if set, i.e. "app/deploy".
+func (a *SecretAccess) String() string {
+	if a.Script == "" {
+		return a.Workspace
+	}
+
+	return a.Workspace + "/" + a.Script
+}
+
+// Allows returns true if the script of the workspace, both by name, may
+// use the secret. If the secret's Allow list is empty, any may use it.
+func (s *Secret) Allows(workspace, script string) bool {
+	if len(s.Allow) == 0 {
+		return true
+	}
+
+	for _, a := range s.Allow {
+		if a.Workspace == workspace && (a.Script == "" || a.Script == script) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Restricted returns true if the secret has an allow list,
+// or its use must be confirmed.
+func (s *Secret) Restricted() bool {
+	return len(s.Allow) > 0 || s.Confirm
+}
+
+// CheckSecretRead checks a secret can be read outside of a script, such as
+// when it's shown, exported or shared. Restricted secrets are refused with
+// ErrSecretRestricted, unless force is set, in which case, if the secret's
+// use must be confirmed, the user is still asked at a prompt.
+func (c *Config) CheckSecretRead(s *Secret, force bool) error {
+	if !s.Restricted() {
+		return nil
+	}
+
+	if !force {
+		return fmt.Errorf("%w: %s", ErrSecretRestricted, s.Name)
+	}
+
+	if !s.Confirm {
+		return nil
+	}
+
+	ok, err := confirm(confirmInput, confirmOutput, fmt.Sprintf("Read the secret %s?", s.Name))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSecretNotConfirmed, s.Name)
+	}
+
+	return nil
+}
+
+// AllowSecret adds the workspace, or one of its scripts, if script is set,
+// to the list of those allowed to use the secret with the given name. Once
+// a secret has an allow list, no other workspaces or scripts may use it.
+func (c *Config) AllowSecret(name, workspace, script string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	if workspace == "" {
+		return ErrSecretAccessWorkspaceEmpty
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range secret.Allow {
+		if a.Workspace == workspace && a.Script == script {
+			return nil
+		}
+	}
+
+	secret.Allow = append(secret.Allow, &SecretAccess{Workspace: workspace, Script: script})
+
+	return store.Put(secret)
+}
+
+// DenySecret removes the workspace, or one of its scripts, from the list
+// of those allowed to use the secret with the given name. If the list is
+// then empty, any workspace may use the secret.
+func (c *Config) DenySecret(name, workspace, script string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	for i, a := range secret.Allow {
+		if a.Workspace == workspace && a.Script == script {
+			secret.Allow = append(secret.Allow[:i], secret.Allow[i+1:]...)
+			return store.Put(secret)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrSecretAccessNotFound, (&SecretAccess{Workspace: workspace, Script: script}).String())
+}
+
+// SetSecretConfirm sets whether the use of the secret with the given
+// name must be confirmed at a prompt, before its value is read.
+func (c *Config) SetSecretConfirm(name string, confirm bool) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	store := c.SecretStore()
+	secret, err := store.Get(name)
+	if err != nil {
+		return err
+	}
+
+	secret.Confirm = confirm
+
+	return store.Put(secret)
+}
+
+// checkAccess returns ErrSecretAccessDenied if the script may not use the
+// secret. If the secret's use must be confirmed, the user is asked at a
+// prompt, once per secret, and ErrSecretNotConfirmed is returned if they
+// refuse. A script without a name, such as when a workspace's environment
+// is read, is only allowed secrets which allow the whole workspace.
+func (s *WorkspaceScript) checkAccess(sec *Secret) error {
+	a := &SecretAccess{Script: s.Name}
+	if s.w != nil {
+		a.Workspace = s.w.Name
+	}
+
+	if !sec.Allows(a.Workspace, a.Script) {
+		return fmt.Errorf("%w: %s by %s", ErrSecretAccessDenied, sec.Name, a.String())
+	}
+
+	if !sec.Confirm || s.c.confirmed[sec.Name] {
+		return nil
+	}
+
+	ok, err := confirm(confirmInput, confirmOutput, fmt.Sprintf("Allow %s to use the secret %s?", a.String(), sec.Name))
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSecretNotConfirmed, sec.Name)
+	}
+
+	if s.c.confirmed == nil {
+		s.c.confirmed = make(map[string]bool)
+	}
+
+	s.c.confirmed[sec.Name] = true
+
+	return nil
+}
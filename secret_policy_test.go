@@ -0,0 +1,196 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setConfirmOutput(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	oldConfirmOutput := confirmOutput
+	confirmOutput = &buf
+
+	t.Cleanup(func() {
+		confirmOutput = oldConfirmOutput
+	})
+
+	return &buf
+}
+
+func TestSecret_Allows(t *testing.T) {
+	t.Run("Given No Allow List", func(t *testing.T) {
+		s := &Secret{Name: "Token"}
+		assert.True(t, s.Allows("app", "build"))
+		assert.True(t, s.Allows("", ""))
+	})
+
+	t.Run("Given Allowed Workspace", func(t *testing.T) {
+		s := &Secret{Name: "Token", Allow: []*SecretAccess{{Workspace: "app"}}}
+		assert.True(t, s.Allows("app", "build"))
+		assert.True(t, s.Allows("app", ""))
+		assert.False(t, s.Allows("api", "build"))
+	})
+
+	t.Run("Given Allowed Script", func(t *testing.T) {
+		s := &Secret{Name: "Token", Allow: []*SecretAccess{{Workspace: "app", Script: "deploy"}}}
+		assert.True(t, s.Allows("app", "deploy"))
+		assert.False(t, s.Allows("app", "build"))
+		assert.False(t, s.Allows("app", ""))
+	})
+}
+
+func TestConfig_CheckSecretRead(t *testing.T) {
+	c := &Config{}
+
+	t.Run("Given Unrestricted Secret", func(t *testing.T) {
+		err := c.CheckSecretRead(&Secret{Name: "Token"}, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Secret Is Restricted", func(t *testing.T) {
+		err := c.CheckSecretRead(&Secret{Name: "Token", Allow: []*SecretAccess{{Workspace: "app"}}}, false)
+		assert.True(t, errors.Is(err, ErrSecretRestricted))
+
+		err = c.CheckSecretRead(&Secret{Name: "Token", Confirm: true}, false)
+		assert.True(t, errors.Is(err, ErrSecretRestricted))
+	})
+
+	t.Run("Given Force", func(t *testing.T) {
+		err := c.CheckSecretRead(&Secret{Name: "Token", Allow: []*SecretAccess{{Workspace: "app"}}}, true)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Given Force Where Confirmation Is Refused", func(t *testing.T) {
+		out := setConfirmOutput(t)
+		setTerminalInput(t, "n")
+
+		err := c.CheckSecretRead(&Secret{Name: "Token", Confirm: true}, true)
+		assert.True(t, errors.Is(err, ErrSecretNotConfirmed))
+		assert.Equal(t, "Read the secret Token? [y/N]: ", out.String())
+	})
+}
+
+func TestConfig_AllowSecret(t *testing.T) {
+	t.Run("Given Workspace And Script", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+		assert.NoError(t, c.AllowSecret("Token", "app", ""))
+		assert.NoError(t, c.AllowSecret("Token", "api", "deploy"))
+		assert.NoError(t, c.AllowSecret("Token", "app", ""))
+		assert.Equal(t, []*SecretAccess{
+			{Workspace: "app"},
+			{Workspace: "api", Script: "deploy"},
+		}, c.Secrets[0].Allow)
+	})
+
+	t.Run("Given Empty Workspace", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+		err := c.AllowSecret("Token", "", "deploy")
+		assert.Equal(t, ErrSecretAccessWorkspaceEmpty, err)
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		c := &Config{}
+		err := c.AllowSecret("Token", "app", "")
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+}
+
+func TestConfig_DenySecret(t *testing.T) {
+	t.Run("Given Allowed Workspace", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{
+			{Name: "Token", Value: "abc", Allow: []*SecretAccess{{Workspace: "app"}, {Workspace: "api"}}},
+		}}
+		assert.NoError(t, c.DenySecret("Token", "app", ""))
+		assert.Equal(t, []*SecretAccess{{Workspace: "api"}}, c.Secrets[0].Allow)
+	})
+
+	t.Run("Where Access Does Not Exist", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{
+			{Name: "Token", Value: "abc", Allow: []*SecretAccess{{Workspace: "app"}}},
+		}}
+		err := c.DenySecret("Token", "app", "deploy")
+		assert.True(t, errors.Is(err, ErrSecretAccessNotFound))
+		assert.Equal(t, "secret: access not found: app/deploy", err.Error())
+	})
+}
+
+func TestConfig_SetSecretConfirm(t *testing.T) {
+	c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+	assert.NoError(t, c.SetSecretConfirm("Token", true))
+	assert.True(t, c.Secrets[0].Confirm)
+
+	assert.NoError(t, c.SetSecretConfirm("Token", false))
+	assert.False(t, c.Secrets[0].Confirm)
+
+	err := c.SetSecretConfirm("Other", true)
+	assert.Equal(t, ErrSecretNotFound, err)
+}
+
+func TestWorkspaceScript_commandArgs_WithSecretPolicy(t *testing.T) {
+	newScript := func(command string, secrets ...*Secret) *WorkspaceScript {
+		c := &Config{Secrets: secrets}
+		w := &Workspace{c: c, Name: "app"}
+		return &WorkspaceScript{c: c, w: w, Name: "build", Command: command}
+	}
+
+	t.Run("Given Allowed Script", func(t *testing.T) {
+		s := newScript("login <secrets.Token>",
+			&Secret{Name: "Token", Value: "abc", Allow: []*SecretAccess{{Workspace: "app", Script: "build"}}})
+
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"login", "abc"}, args)
+	})
+
+	t.Run("Where Script Is Not Allowed", func(t *testing.T) {
+		s := newScript("login <secrets.Token>",
+			&Secret{Name: "Token", Value: "abc", Allow: []*SecretAccess{{Workspace: "app", Script: "deploy"}}})
+
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrSecretAccessDenied))
+		assert.Contains(t, err.Error(), "secret: not allowed to be used here: Token by app/build")
+	})
+
+	t.Run("Given Confirmation", func(t *testing.T) {
+		out := setConfirmOutput(t)
+		setTerminalInput(t, "y")
+
+		s := newScript("login <secrets.Token> <secrets.Token>",
+			&Secret{Name: "Token", Value: "abc", Confirm: true})
+
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"login", "abc", "abc"}, args)
+		assert.Equal(t, "Allow app/build to use the secret Token? [y/N]: ", out.String())
+	})
+
+	t.Run("Where Confirmation Is Refused", func(t *testing.T) {
+		setConfirmOutput(t)
+		setTerminalInput(t, "")
+
+		s := newScript("login <secrets.Token>",
+			&Secret{Name: "Token", Value: "abc", Confirm: true})
+
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrSecretNotConfirmed))
+	})
+
+	t.Run("Where Input Is Not A Terminal", func(t *testing.T) {
+		setConfirmOutput(t)
+		oldConfirmInput := confirmInput
+		confirmInput = pipeInput(t, "y\n")
+		defer func() { confirmInput = oldConfirmInput }()
+
+		s := newScript("login <secrets.Token>",
+			&Secret{Name: "Token", Value: "abc", Confirm: true})
+
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrConfirmRequired))
+	})
+}
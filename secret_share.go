@@ -0,0 +1,121 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/reecerussell/passport/template"
+	"gopkg.in/yaml.v3"
+)
+
+// Common share errors.
+var (
+	ErrShareNoSecrets    = errors.New("share: no secrets specified")
+	ErrShareNoRecipients = errors.New("share: no public keys specified")
+	ErrBundleInvalid     = errors.New("share: bundle could not be opened")
+	ErrBundleNameInvalid = errors.New("share: names may only contain letters, digits, '-' and '_'")
+)
+
+// SecretBundle is a set of secrets, shared by another user. Bundles are
+// encrypted to the public keys of their recipients, so can be sent over
+// any channel, i.e. chat or email.
+type SecretBundle struct {
+	// From is the public key of the sender. As anyone can encrypt a
+	// bundle, this is only informational, and isn't verified.
+	From      string         `yaml:"from"`
+	CreatedAt time.Time      `yaml:"created_at"`
+	Secrets   []*SecretValue `yaml:"secrets"`
+}
+
+// ShareSecrets returns a bundle of the secrets with the given names,
+// encrypted to the public keys, to. The bundle is ASCII armored. Secrets
+// restricted by a policy are only shared if force is set, and see
+// CheckSecretRead.
+func (c *Config) ShareSecrets(names, to []string, from *Identity, force bool, cp CryptoProvider) ([]byte, error) {
+	if len(names) == 0 {
+		return nil, ErrShareNoSecrets
+	}
+
+	if len(to) == 0 {
+		return nil, ErrShareNoRecipients
+	}
+
+	recipients, err := parseRecipients(to)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &SecretBundle{
+		From:      from.PublicKey,
+		CreatedAt: now().UTC().Truncate(time.Second),
+	}
+
+	for _, name := range names {
+		s, err := c.GetSecret(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, name)
+		}
+
+		err = c.CheckSecretRead(s, force)
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := s.GetValue(cp)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Secrets = append(b.Secrets, &SecretValue{Name: s.Name, Value: v})
+	}
+
+	data, _ := yaml.Marshal(b)
+
+	return encryptArmored(data, recipients...)
+}
+
+// OpenBundle decrypts a bundle shared with the identity.
+func (id *Identity) OpenBundle(data []byte) (*SecretBundle, error) {
+	data, err := decryptArmored(data, id.x25519)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBundleInvalid, err.Error())
+	}
+
+	b := &SecretBundle{}
+	err = yaml.Unmarshal(data, b)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrBundleInvalid, err.Error())
+	}
+
+	return b, nil
+}
+
+// ReceiveSecrets adds each of the bundle's secrets to the config,
+// using AddSecret. If any of the names can't be used in a template, none
+// of the secrets are added, and ErrBundleNameInvalid is returned, listing
+// all of them. If any of the secrets already exist, an error is returned.
+func (c *Config) ReceiveSecrets(b *SecretBundle, encrypt bool, cp CryptoProvider) error {
+	var invalid []string
+	for _, s := range b.Secrets {
+		if s == nil {
+			invalid = append(invalid, `""`)
+		} else if !template.ValidName(s.Name) {
+			invalid = append(invalid, fmt.Sprintf("%q", s.Name))
+		}
+	}
+
+	if len(invalid) > 0 {
+		return fmt.Errorf("%w: %s", ErrBundleNameInvalid, strings.Join(invalid, ", "))
+	}
+
+	for _, s := range b.Secrets {
+		err := c.AddSecret(s.Name, s.Value, encrypt, cp)
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, s.Name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package passport
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestIdentity returns a new Identity, for use in tests.
+func newTestIdentity(t *testing.T) *Identity {
+	x, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Identity{PublicKey: x.Recipient().String(), x25519: x}
+}
+
+func TestConfig_ShareSecrets(t *testing.T) {
+	setNow(t, time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC))
+
+	from := newTestIdentity(t)
+	to := newTestIdentity(t)
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "A", Value: "one"},
+			{Name: "B", Value: "two\nlines"},
+		},
+	}
+
+	t.Run("Given Recipient", func(t *testing.T) {
+		data, err := c.ShareSecrets([]string{"A", "B"}, []string{to.PublicKey}, from, false, nil)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(data), "-----BEGIN AGE ENCRYPTED FILE-----"))
+
+		b, err := to.OpenBundle(append([]byte("\n  "), data...))
+		assert.NoError(t, err)
+		assert.Equal(t, &SecretBundle{
+			From:      from.PublicKey,
+			CreatedAt: time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+			Secrets: []*SecretValue{
+				{Name: "A", Value: "one"},
+				{Name: "B", Value: "two\nlines"},
+			},
+		}, b)
+
+		b, err = from.OpenBundle(data)
+		assert.Nil(t, b)
+		assert.True(t, errors.Is(err, ErrBundleInvalid))
+	})
+
+	t.Run("Given Invalid Recipient", func(t *testing.T) {
+		data, err := c.ShareSecrets([]string{"A"}, []string{"ssh-rsa AAAA"}, from, false, nil)
+		assert.Nil(t, data)
+		assert.True(t, errors.Is(err, ErrRecipientInvalid))
+	})
+
+	t.Run("Given No Recipients", func(t *testing.T) {
+		_, err := c.ShareSecrets([]string{"A"}, nil, from, false, nil)
+		assert.Equal(t, ErrShareNoRecipients, err)
+	})
+
+	t.Run("Given No Secrets", func(t *testing.T) {
+		_, err := c.ShareSecrets(nil, []string{to.PublicKey}, from, false, nil)
+		assert.Equal(t, ErrShareNoSecrets, err)
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		_, err := c.ShareSecrets([]string{"C"}, []string{to.PublicKey}, from, false, nil)
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+
+	t.Run("Where Secret Is Restricted", func(t *testing.T) {
+		c := &Config{
+			Secrets: []*Secret{{Name: "A", Value: "1", Allow: []*SecretAccess{{Workspace: "app"}}}},
+		}
+
+		_, err := c.ShareSecrets([]string{"A"}, []string{to.PublicKey}, from, false, nil)
+		assert.True(t, errors.Is(err, ErrSecretRestricted))
+
+		data, err := c.ShareSecrets([]string{"A"}, []string{to.PublicKey}, from, true, nil)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, data)
+	})
+}
+
+func TestIdentity_OpenBundle(t *testing.T) {
+	t.Run("Given Invalid Bundle", func(t *testing.T) {
+		b, err := newTestIdentity(t).OpenBundle([]byte("not a bundle"))
+		assert.Nil(t, b)
+		assert.True(t, errors.Is(err, ErrBundleInvalid))
+	})
+}
+
+func TestConfig_ReceiveSecrets(t *testing.T) {
+	b := &SecretBundle{
+		Secrets: []*SecretValue{
+			{Name: "A", Value: "one"},
+		},
+	}
+
+	t.Run("Where Secrets Do Not Exist", func(t *testing.T) {
+		c := &Config{}
+		err := c.ReceiveSecrets(b, false, nil)
+		assert.NoError(t, err)
+		assert.Len(t, c.Secrets, 1)
+		assert.Equal(t, "one", c.Secrets[0].Value)
+	})
+
+	t.Run("Where Secret Exists", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "A", Value: "old"}}}
+		err := c.ReceiveSecrets(b, false, nil)
+		assert.True(t, errors.Is(err, ErrSecretAlreadyExists))
+		assert.Equal(t, "secret: already exists: A", err.Error())
+	})
+
+	t.Run("Given Invalid Names", func(t *testing.T) {
+		c := &Config{}
+		err := c.ReceiveSecrets(&SecretBundle{
+			Secrets: []*SecretValue{
+				{Name: "A", Value: "one"},
+				{Name: "db.host", Value: "localhost"},
+				nil,
+				{Name: "B C", Value: "two"},
+			},
+		}, false, nil)
+		assert.True(t, errors.Is(err, ErrBundleNameInvalid))
+		assert.Contains(t, err.Error(), `"db.host", "", "B C"`)
+		assert.Empty(t, c.Secrets)
+	})
+}
@@ -0,0 +1,148 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Prefixes of a secret's source, which determine how its value is read.
+const (
+	SecretSourceFile = "file:"
+	SecretSourceExec = "exec:"
+)
+
+// DefaultSecretSourceTimeout is the time a secret's exec source is given
+// to run, when the secret does not specify a timeout.
+const DefaultSecretSourceTimeout = 30 * time.Second
+
+// Common secret source errors.
+var (
+	ErrSecretSourceInvalid  = errors.New("secret: source must start with file: or exec:")
+	ErrSecretTimeoutInvalid = errors.New("secret: timeout is not a valid duration")
+	ErrSecretSourceFailed   = errors.New("secret: failed to read source")
+)
+
+// validateSecretSource checks that source has a valid prefix and target.
+func validateSecretSource(source string) error {
+	for _, prefix := range []string{SecretSourceFile, SecretSourceExec} {
+		if strings.HasPrefix(source, prefix) && strings.TrimSpace(source[len(prefix):]) != "" {
+			return nil
+		}
+	}
+
+	return ErrSecretSourceInvalid
+}
+
+// timeout returns the time the secret's exec source is given to run.
+func (s *Secret) timeout() (time.Duration, error) {
+	if s.Timeout == "" {
+		return DefaultSecretSourceTimeout, nil
+	}
+
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return 0, ErrSecretTimeoutInvalid
+	}
+
+	return d, nil
+}
+
+// readSource reads the value of the secret from its source, caching it,
+// so the source is only read once. A trailing new line is removed.
+func (s *Secret) readSource() (string, error) {
+	if s.resolved {
+		return s.resolvedValue, nil
+	}
+
+	var v []byte
+	var err error
+	switch {
+	case strings.HasPrefix(s.Source, SecretSourceFile):
+		v, err = os.ReadFile(expandPath(strings.TrimSpace(s.Source[len(SecretSourceFile):])))
+	case strings.HasPrefix(s.Source, SecretSourceExec):
+		v, err = s.exec(strings.TrimSpace(s.Source[len(SecretSourceExec):]))
+	default:
+		err = ErrSecretSourceInvalid
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", ErrSecretSourceFailed, s.Name, err)
+	}
+
+	s.resolvedValue = strings.TrimRight(string(v), "\r\n")
+	s.resolved = true
+
+	return s.resolvedValue, nil
+}
+
+// exec runs command, returning its output. If the command does not finish
+// within the secret's timeout, it is killed, along with any processes it
+// started, which may otherwise keep its output open.
+func (s *Secret) exec(command string) ([]byte, error) {
+	timeout, err := s.timeout()
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := splitCommandToArgs(command)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	setProcessGroup(cmd)
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err = <-done:
+	case <-timer.C:
+		// The command is left to be reaped by Wait, as a process which
+		// couldn't be killed may still hold its output open.
+		_ = killProcessGroup(cmd)
+		return nil, fmt.Errorf("timed out after %s", timeout)
+	}
+
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%v: %s", err, msg)
+		}
+
+		return nil, err
+	}
+
+	return stdout.Bytes(), nil
+}
+
+// expandPath expands environment variables and a leading ~ in path.
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err == nil {
+			path = filepath.Join(home, path[1:])
+		}
+	}
+
+	return path
+}
@@ -0,0 +1,136 @@
+package passport
+
+import (
+	"errors"
+	"os"
+	"path"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret_GetValue_Source(t *testing.T) {
+	t.Run("Given File Source", func(t *testing.T) {
+		testPath := path.Join(t.TempDir(), "token")
+		err := os.WriteFile(testPath, []byte("abc123\n"), 0600)
+		if err != nil {
+			panic(err)
+		}
+
+		s := &Secret{Name: "Token", Source: "file:" + testPath}
+		v, err := s.GetValue(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", v)
+
+		// the value is cached, so changes to the file are not seen.
+		os.WriteFile(testPath, []byte("changed"), 0600)
+
+		v, err = s.GetValue(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc123", v)
+	})
+
+	t.Run("Where File Does Not Exist", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "file:" + path.Join(t.TempDir(), "missing")}
+		v, err := s.GetValue(nil)
+		assert.Equal(t, "", v)
+		assert.True(t, errors.Is(err, ErrSecretSourceFailed))
+		assert.Contains(t, err.Error(), "Token")
+	})
+
+	t.Run("Given Invalid Source", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "http://example.com"}
+		_, err := s.GetValue(nil)
+		assert.True(t, errors.Is(err, ErrSecretSourceFailed))
+	})
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	t.Run("Given Exec Source", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "exec:echo 'hello world'"}
+		v, err := s.GetValue(nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "hello world", v)
+	})
+
+	t.Run("Where Command Fails", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "exec:sh -c 'echo denied >&2; exit 1'"}
+		v, err := s.GetValue(nil)
+		assert.Equal(t, "", v)
+		assert.True(t, errors.Is(err, ErrSecretSourceFailed))
+		assert.Contains(t, err.Error(), "denied")
+	})
+
+	t.Run("Where Command Times Out", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "exec:sleep 5", Timeout: "100ms"}
+		_, err := s.GetValue(nil)
+		assert.True(t, errors.Is(err, ErrSecretSourceFailed))
+		assert.Contains(t, err.Error(), "timed out after 100ms")
+	})
+
+	t.Run("Where Child Process Keeps Output Open", func(t *testing.T) {
+		s := &Secret{Name: "Token", Source: "exec:sh -c 'sleep 5 & echo started'", Timeout: "100ms"}
+		start := time.Now()
+		_, err := s.GetValue(nil)
+		assert.True(t, errors.Is(err, ErrSecretSourceFailed))
+		assert.Contains(t, err.Error(), "timed out after 100ms")
+		assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	})
+}
+
+func TestConfig_AddSecretSource(t *testing.T) {
+	testNow := time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC)
+	setNow(t, testNow)
+
+	t.Run("Given Valid Source", func(t *testing.T) {
+		c := &Config{}
+		err := c.AddSecretSource("Token", "exec:aws ecr get-login-password", "10s")
+		assert.NoError(t, err)
+		assert.Equal(t, []*Secret{
+			{
+				Name:      "Token",
+				Source:    "exec:aws ecr get-login-password",
+				Timeout:   "10s",
+				Version:   1,
+				UpdatedAt: testNow,
+			},
+		}, c.Secrets)
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		c := &Config{}
+		err := c.AddSecretSource("", "file:~/token", "")
+		assert.Equal(t, ErrSecretNameEmpty, err)
+	})
+
+	t.Run("Given Invalid Source", func(t *testing.T) {
+		c := &Config{}
+		err := c.AddSecretSource("Token", "file: ", "")
+		assert.Equal(t, ErrSecretSourceInvalid, err)
+	})
+
+	t.Run("Given Invalid Timeout", func(t *testing.T) {
+		c := &Config{}
+		err := c.AddSecretSource("Token", "exec:whoami", "soon")
+		assert.Equal(t, ErrSecretTimeoutInvalid, err)
+	})
+
+	t.Run("Where Secret Exists", func(t *testing.T) {
+		c := &Config{Secrets: []*Secret{{Name: "Token", Value: "abc"}}}
+		err := c.AddSecretSource("Token", "file:~/token", "")
+		assert.Equal(t, ErrSecretAlreadyExists, err)
+	})
+}
+
+func TestExpandPath(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	t.Setenv("TEST_EXPAND_PATH", "dir")
+
+	assert.Equal(t, path.Join(home, "token"), expandPath("~/token"))
+	assert.Equal(t, "dir/token", expandPath("$TEST_EXPAND_PATH/token"))
+	assert.Equal(t, "/etc/~token", expandPath("/etc/~token"))
+}
@@ -0,0 +1,22 @@
+//go:build windows
+// +build windows
+
+package passport
+
+import (
+	"os/exec"
+	"strconv"
+)
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// killProcessGroup kills cmd, along with any processes it started,
+// as Windows doesn't kill child processes with their parent.
+func killProcessGroup(cmd *exec.Cmd) error {
+	err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(cmd.Process.Pid)).Run()
+	if err != nil {
+		return cmd.Process.Kill()
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package passport
+
+// SecretStore is an interface used to abstract the storage of secrets,
+// allowing secrets to be kept somewhere other than the config file.
+type SecretStore interface {
+	// Get returns the secret with the given name. If the secret
+	// does not exist, ErrSecretNotFound is returned.
+	Get(name string) (*Secret, error)
+
+	// Put stores the secret, replacing any secret with the same name.
+	Put(secret *Secret) error
+
+	// Delete removes the secret with the given name. If the secret
+	// does not exist, ErrSecretNotFound is returned.
+	Delete(name string) error
+
+	// List returns all of the secrets in the store.
+	List() ([]*Secret, error)
+}
+
+// historyStore is implemented by SecretStores which report whether they
+// keep the history of secrets. Stores which don't implement it do.
+type historyStore interface {
+	keepsHistory() bool
+}
+
+// keepsHistory determines whether the previous values of secrets
+// are kept in store, when they're changed.
+func keepsHistory(store SecretStore) bool {
+	h, ok := store.(historyStore)
+
+	return !ok || h.keepsHistory()
+}
+
+// yamlSecretStore is the default SecretStore, which keeps secrets in
+// the config file, either those of the config, or of a workspace.
+type yamlSecretStore struct {
+	secrets *[]*Secret
+}
+
+// Get returns the secret from the config file with the given name.
+func (s *yamlSecretStore) Get(name string) (*Secret, error) {
+	for _, secret := range *s.secrets {
+		if secret.Name == name {
+			return secret, nil
+		}
+	}
+
+	return nil, ErrSecretNotFound
+}
+
+// Put adds the secret to the config file, replacing any
+// secret with the same name. The config must then be saved.
+func (s *yamlSecretStore) Put(secret *Secret) error {
+	for i, existing := range *s.secrets {
+		if existing.Name == secret.Name {
+			(*s.secrets)[i] = secret
+			return nil
+		}
+	}
+
+	*s.secrets = append(*s.secrets, secret)
+
+	return nil
+}
+
+// Delete removes the secret with the given name from the
+// config file. The config must then be saved.
+func (s *yamlSecretStore) Delete(name string) error {
+	secrets := *s.secrets
+	for i := 0; i < len(secrets); i++ {
+		if secrets[i].Name == name {
+			*s.secrets = append(secrets[:i], secrets[i+1:]...)
+
+			return nil
+		}
+	}
+
+	return ErrSecretNotFound
+}
+
+// List returns the secrets in the config file.
+func (s *yamlSecretStore) List() ([]*Secret, error) {
+	secrets := make([]*Secret, len(*s.secrets))
+	copy(secrets, *s.secrets)
+
+	return secrets, nil
+}
+
+// errSecretStore is a SecretStore which returns err from each function,
+// used when the store can't be opened.
+type errSecretStore struct {
+	err error
+}
+
+func (s *errSecretStore) Get(string) (*Secret, error) { return nil, s.err }
+func (s *errSecretStore) Put(*Secret) error           { return s.err }
+func (s *errSecretStore) Delete(string) error         { return s.err }
+func (s *errSecretStore) List() ([]*Secret, error)    { return nil, s.err }
+
+// SecretStore returns the store used to hold the config's secrets. By
+// default, secrets are kept in the config file, unless Vault is configured.
+// If UseWorkspaceSecrets has been called, the workspace's secrets are
+// used, which are always kept in the config file.
+func (c *Config) SecretStore() SecretStore {
+	if c.secretScope == "" {
+		return c.globalSecretStore()
+	}
+
+	w, err := c.GetWorkspace(c.secretScope)
+	if err != nil {
+		return &errSecretStore{err: err}
+	}
+
+	return &yamlSecretStore{secrets: &w.Secrets}
+}
+
+// globalSecretStore returns the store used to hold the
+// secrets of the config, rather than of a workspace. The
+// Vault store is kept until the config is reloaded, as its
+// Vault config may then have changed.
+func (c *Config) globalSecretStore() SecretStore {
+	if c.secretStore != nil {
+		return c.secretStore
+	}
+
+	if c.Vault != nil {
+		if c.vaultStore == nil {
+			c.vaultStore = NewVaultSecretStore(c.Vault, nil)
+		}
+
+		return c.vaultStore
+	}
+
+	return &yamlSecretStore{secrets: &c.Secrets}
+}
+
+// UseWorkspaceSecrets sets the workspace, by its path, whose secrets are
+// used by functions such as AddSecret and SetSecret, in place of the
+// global secrets. GetSecret falls back to the global secrets. If path is
+// empty, the global secrets are used.
+func (c *Config) UseWorkspaceSecrets(path string) {
+	c.secretScope = path
+}
+
+// SetSecretStore sets the store used to hold the config's secrets, in
+// place of the config file. If store is nil, the config file is used.
+func (c *Config) SetSecretStore(store SecretStore) {
+	c.secretStore = store
+}
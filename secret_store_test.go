@@ -0,0 +1,314 @@
+package passport
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+// testSecretStore is an in-memory SecretStore, which returns err,
+// if set, from each of its methods.
+type testSecretStore struct {
+	secrets map[string]*Secret
+	err     error
+}
+
+func (s *testSecretStore) Get(name string) (*Secret, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	secret, ok := s.secrets[name]
+	if !ok {
+		return nil, ErrSecretNotFound
+	}
+
+	return secret, nil
+}
+
+func (s *testSecretStore) Put(secret *Secret) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.secrets[secret.Name] = secret
+
+	return nil
+}
+
+func (s *testSecretStore) Delete(name string) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	if _, ok := s.secrets[name]; !ok {
+		return ErrSecretNotFound
+	}
+
+	delete(s.secrets, name)
+
+	return nil
+}
+
+func (s *testSecretStore) List() ([]*Secret, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var secrets []*Secret
+	for _, secret := range s.secrets {
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+func TestYamlSecretStore(t *testing.T) {
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "MySecret", Value: "Hello"},
+		},
+	}
+	store := c.SecretStore()
+
+	t.Run("Get Existing Secret", func(t *testing.T) {
+		s, err := store.Get("MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello", s.Value)
+	})
+
+	t.Run("Get Missing Secret", func(t *testing.T) {
+		s, err := store.Get("Other")
+		assert.Nil(t, s)
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+
+	t.Run("Put Replaces Existing Secret", func(t *testing.T) {
+		err := store.Put(&Secret{Name: "MySecret", Value: "World"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(c.Secrets))
+		assert.Equal(t, "World", c.Secrets[0].Value)
+	})
+
+	t.Run("Put Adds New Secret", func(t *testing.T) {
+		err := store.Put(&Secret{Name: "Other", Value: "Value"})
+		assert.NoError(t, err)
+
+		secrets, err := store.List()
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(secrets))
+		assert.Equal(t, "Other", secrets[1].Name)
+	})
+
+	t.Run("Delete Existing Secret", func(t *testing.T) {
+		err := store.Delete("Other")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(c.Secrets))
+	})
+
+	t.Run("Delete Missing Secret", func(t *testing.T) {
+		err := store.Delete("Other")
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+}
+
+func TestConfig_SetSecretStore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	testNow := time.Date(2021, 5, 1, 12, 30, 15, 0, time.UTC)
+	setNow(t, testNow)
+
+	store := &testSecretStore{secrets: make(map[string]*Secret)}
+	c := &Config{}
+	c.SetSecretStore(store)
+
+	t.Run("AddSecret Puts Secret In Store", func(t *testing.T) {
+		cp := mock.NewMockCryptoProvider(ctrl)
+		cp.EXPECT().EncryptString("Hello").Return("encrypted", nil)
+
+		err := c.AddSecret("MySecret", "Hello", true, cp)
+		assert.NoError(t, err)
+		assert.Equal(t, &Secret{
+			Name:      "MySecret",
+			Value:     "encrypted",
+			Secure:    true,
+			Version:   1,
+			UpdatedAt: testNow,
+		}, store.secrets["MySecret"])
+		assert.Empty(t, c.Secrets)
+	})
+
+	t.Run("AddSecret Where Secret Exists", func(t *testing.T) {
+		err := c.AddSecret("MySecret", "Hello", false, nil)
+		assert.Equal(t, ErrSecretAlreadyExists, err)
+	})
+
+	t.Run("GetSecret Gets Secret From Store", func(t *testing.T) {
+		s, err := c.GetSecret("MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "encrypted", s.Value)
+	})
+
+	t.Run("ListSecrets Lists Secrets In Store", func(t *testing.T) {
+		secrets, err := c.ListSecrets()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(secrets))
+	})
+
+	t.Run("RemoveSecret Deletes Secret From Store", func(t *testing.T) {
+		err := c.RemoveSecret("MySecret")
+		assert.NoError(t, err)
+		assert.Empty(t, store.secrets)
+	})
+
+	t.Run("Where Store Fails", func(t *testing.T) {
+		testErr := errors.New("store: error")
+		c := &Config{}
+		c.SetSecretStore(&testSecretStore{err: testErr})
+
+		_, err := c.GetSecret("MySecret")
+		assert.Equal(t, testErr, err)
+
+		err = c.AddSecret("MySecret", "Hello", false, nil)
+		assert.Equal(t, testErr, err)
+
+		_, err = c.ListSecrets()
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("Run Resolves Secrets From Store", func(t *testing.T) {
+		store.secrets["Token"] = &Secret{Name: "Token", Value: "abc"}
+		w := &Workspace{
+			c: c,
+			Scripts: []*WorkspaceScript{
+				{Name: "build", Command: "echo <secrets.Token>"},
+			},
+		}
+
+		s, err := w.GetScript("build")
+		assert.NoError(t, err)
+
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"echo", "abc"}, args)
+	})
+}
+
+func TestConfig_UseWorkspaceSecrets(t *testing.T) {
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "DB_PASSWORD", Value: "global"},
+			{Name: "TOKEN", Value: "token"},
+		},
+		Workspaces: []*Workspace{
+			{Name: "app", Path: "/c/app"},
+		},
+	}
+	c.UseWorkspaceSecrets("/c/app")
+	w := c.Workspaces[0]
+
+	t.Run("AddSecret Adds Secret To Workspace", func(t *testing.T) {
+		err := c.AddSecret("DB_PASSWORD", "workspace", false, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(w.Secrets))
+		assert.Equal(t, "workspace", w.Secrets[0].Value)
+		assert.Equal(t, 2, len(c.Secrets))
+	})
+
+	t.Run("GetSecret Prefers Workspace Secret", func(t *testing.T) {
+		s, err := c.GetSecret("DB_PASSWORD")
+		assert.NoError(t, err)
+		assert.Equal(t, "workspace", s.Value)
+	})
+
+	t.Run("GetSecret Falls Back To Global Secret", func(t *testing.T) {
+		s, err := c.GetSecret("TOKEN")
+		assert.NoError(t, err)
+		assert.Equal(t, "token", s.Value)
+	})
+
+	t.Run("ListSecrets Lists Global Secrets", func(t *testing.T) {
+		secrets, err := c.ListSecrets()
+		assert.NoError(t, err)
+		assert.Equal(t, c.Secrets, secrets)
+	})
+
+	t.Run("SetSecret Does Not Set Global Secret", func(t *testing.T) {
+		err := c.SetSecret("TOKEN", "other", false, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "token", c.Secrets[1].Value)
+		assert.Equal(t, "other", w.Secrets[1].Value)
+	})
+
+	t.Run("RemoveSecret Removes Workspace Secret", func(t *testing.T) {
+		c := &Config{
+			Secrets: []*Secret{{Name: "DB_PASSWORD", Value: "global"}},
+			Workspaces: []*Workspace{
+				{Name: "app", Path: "/c/app", Secrets: []*Secret{{Name: "DB_PASSWORD", Value: "workspace"}}},
+			},
+		}
+		c.UseWorkspaceSecrets("/c/app")
+
+		err := c.RemoveSecret("DB_PASSWORD")
+		assert.NoError(t, err)
+		assert.Empty(t, c.Workspaces[0].Secrets)
+		assert.Equal(t, 1, len(c.Secrets))
+	})
+
+	t.Run("Where Workspace Does Not Exist", func(t *testing.T) {
+		c := &Config{}
+		c.UseWorkspaceSecrets("/c/other")
+
+		err := c.AddSecret("DB_PASSWORD", "workspace", false, nil)
+		assert.Equal(t, ErrWorkspaceNotFound, err)
+
+		_, err = c.GetSecret("DB_PASSWORD")
+		assert.Equal(t, ErrWorkspaceNotFound, err)
+	})
+
+	t.Run("Update Keeps Workspace Secrets In Use", func(t *testing.T) {
+		dir := t.TempDir()
+		fs := NewFilesys()
+		assert.NoError(t, EnsureConfigFile(dir, fs))
+
+		c, err := LoadConfig(dir, fs, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, c.AddWorkspace("app", "/c/app"))
+		assert.NoError(t, c.Save())
+
+		c.UseWorkspaceSecrets("/c/app")
+		err = c.Update(func(c *Config) error {
+			return c.AddSecret("DB_PASSWORD", "workspace", false, nil)
+		})
+		assert.NoError(t, err)
+
+		c, err = LoadConfig(dir, fs, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, c.Secrets)
+		assert.Equal(t, "DB_PASSWORD", c.Workspaces[0].Secrets[0].Name)
+	})
+}
+
+func TestWorkspaceScript_commandArgs_WithWorkspaceSecrets(t *testing.T) {
+	c := &Config{
+		Secrets: []*Secret{
+			{Name: "DB_PASSWORD", Value: "global"},
+			{Name: "TOKEN", Value: "token"},
+		},
+	}
+	w := &Workspace{
+		c:       c,
+		Secrets: []*Secret{{Name: "DB_PASSWORD", Value: "workspace"}},
+	}
+	s := &WorkspaceScript{c: c, w: w, Command: "login <secrets.DB_PASSWORD> <secrets.TOKEN>"}
+
+	args, err := s.commandArgs(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"login", "workspace", "token"}, args)
+}
@@ -0,0 +1,140 @@
+package passport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Names of the config stores, which are layered by LoadStores.
+const (
+	StorePersonal = "personal"
+	StoreTeam     = "team"
+	StoreSystem   = "system"
+)
+
+// Common store errors.
+var (
+	ErrStoreNameEmpty = errors.New("store: name is empty")
+	ErrStoreNotFound  = errors.New("store: not found")
+	ErrStoreReadOnly  = errors.New("store: changes can only be saved to the selected store")
+)
+
+// Store is a directory containing a config file, which can be layered
+// with the config files of other stores.
+type Store struct {
+	Name string
+	Dir  string
+}
+
+// LoadStores loads the config files of the given stores, which are ordered
+// by precedence, highest first, and layers them. The returned config holds
+// the config file of the store with the given name, which is where changes
+// are saved. Secrets, workspaces, variables and profiles are resolved from
+// the first store which defines them. Stores, other than the selected
+// one, are skipped if they have no config file.
+func LoadStores(stores []*Store, name string, fs Filesys, cp CryptoProvider) (*Config, error) {
+	if name == "" {
+		return nil, ErrStoreNameEmpty
+	}
+
+	var c *Config
+	layers := make([]*Config, 0, len(stores))
+	for _, s := range stores {
+		if s.Name == name {
+			c = &Config{
+				configDir: s.Dir,
+				fs:        fs,
+				cp:        cp,
+				store:     s.Name,
+			}
+			layers = append(layers, c)
+			continue
+		}
+
+		exists, err := fs.FileExists(path.Join(s.Dir, configFilename))
+		if err != nil {
+			return nil, err
+		}
+
+		if !exists {
+			continue
+		}
+
+		layers = append(layers, &Config{
+			configDir: s.Dir,
+			fs:        fs,
+			cp:        cp,
+			store:     s.Name,
+			readOnly:  true,
+		})
+	}
+
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrStoreNotFound, name)
+	}
+
+	c.layers = layers
+
+	err := c.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Store returns the name of the store the config is saved to. If the
+// config was loaded by LoadConfig, this is empty.
+func (c *Config) Store() string {
+	return c.store
+}
+
+// stack returns the configs of every store, ordered by precedence. If the
+// config was not loaded by LoadStores, only the config itself is returned.
+func (c *Config) stack() []*Config {
+	if c.layers == nil {
+		return []*Config{c}
+	}
+
+	return c.layers
+}
+
+// loadLayers reloads the configs of the other stores, taking a snapshot
+// of each, so changes made to them can be detected when c is saved.
+func (c *Config) loadLayers() error {
+	for _, l := range c.layers {
+		if l == c {
+			continue
+		}
+
+		err := l.load()
+		if err != nil {
+			return fmt.Errorf("store: failed to load %s: %w", l.store, err)
+		}
+
+		l.snapshot, _ = yaml.Marshal(l)
+	}
+
+	return nil
+}
+
+// checkLayers returns ErrStoreReadOnly if the config of another store
+// has been changed, as the change would otherwise be lost when c is saved.
+func (c *Config) checkLayers() error {
+	for _, l := range c.layers {
+		if l == c {
+			continue
+		}
+
+		data, _ := yaml.Marshal(l)
+		if !bytes.Equal(data, l.snapshot) {
+			return fmt.Errorf("%w, use --store %s to change the %s store", ErrStoreReadOnly, l.store, l.store)
+		}
+	}
+
+	return nil
+}
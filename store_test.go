@@ -0,0 +1,238 @@
+package passport
+
+import (
+	"errors"
+	"path"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+const (
+	testPersonalConfig = `version: 1
+secrets:
+- name: Token
+  value: personal
+workspaces:
+- name: app
+  path: /c/app
+  scripts:
+  - name: build
+    command: echo <vars.REGISTRY> <vars.TAG>
+vars:
+  TAG: dev
+profiles:
+- name: prod
+  vars:
+    TAG: personal
+`
+	testTeamConfig = `version: 1
+secrets:
+- name: Token
+  value: team
+- name: Shared
+  value: team
+workspaces:
+- name: app
+  path: /c/app
+  scripts: []
+- name: api
+  path: /c/api
+  scripts: []
+vars:
+  REGISTRY: registry.example.com
+  TAG: latest
+profiles:
+- name: prod
+  vars:
+    TAG: team
+    REGION: eu
+`
+)
+
+func testStores() []*Store {
+	return []*Store{
+		{Name: StorePersonal, Dir: "personal"},
+		{Name: StoreTeam, Dir: "team"},
+		{Name: StoreSystem, Dir: "system"},
+	}
+}
+
+func TestLoadStores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("Layers Stores By Precedence", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(path.Join("team", configFilename)).Return(true, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(false, nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil)
+		fs.EXPECT().Read(path.Join("team", configFilename)).Return([]byte(testTeamConfig), nil)
+
+		c, err := LoadStores(testStores(), StorePersonal, fs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, StorePersonal, c.Store())
+		assert.Equal(t, 1, len(c.Secrets))
+
+		s, err := c.GetSecret("Token")
+		assert.NoError(t, err)
+		assert.Equal(t, "personal", s.Value)
+
+		s, err = c.GetSecret("Shared")
+		assert.NoError(t, err)
+		assert.Equal(t, "team", s.Value)
+
+		secrets, err := c.ListSecrets()
+		assert.NoError(t, err)
+
+		names := []string{}
+		for _, s := range secrets {
+			names = append(names, s.Name)
+		}
+		assert.Equal(t, []string{"Token", "Shared"}, names)
+
+		w, err := c.GetWorkspace("/c/app")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(w.Scripts))
+
+		_, err = c.GetWorkspace("/c/api")
+		assert.NoError(t, err)
+	})
+
+	t.Run("Resolves Vars And Profiles By Precedence", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(path.Join("team", configFilename)).Return(true, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(false, nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil)
+		fs.EXPECT().Read(path.Join("team", configFilename)).Return([]byte(testTeamConfig), nil)
+
+		c, err := LoadStores(testStores(), StorePersonal, fs, nil)
+		assert.NoError(t, err)
+
+		w, _ := c.GetWorkspace("/c/app")
+		s, _ := w.GetScript("build")
+
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"echo", "registry.example.com", "dev"}, args)
+
+		c.UseProfile("prod")
+		p, err := s.activeProfile()
+		assert.NoError(t, err)
+		assert.Equal(t, VarSet{"TAG": "personal", "REGION": "eu"}, p.Vars)
+	})
+
+	t.Run("Where Selected Store Is Lower Precedence", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(path.Join("personal", configFilename)).Return(true, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(false, nil)
+		fs.EXPECT().Read(path.Join("team", configFilename)).Return([]byte(testTeamConfig), nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil)
+
+		c, err := LoadStores(testStores(), StoreTeam, fs, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(c.Secrets))
+
+		s, err := c.GetSecret("Token")
+		assert.NoError(t, err)
+		assert.Equal(t, "personal", s.Value)
+	})
+
+	t.Run("Given Unknown Store", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(gomock.Any()).Return(false, nil).Times(3)
+
+		c, err := LoadStores(testStores(), "other", fs, nil)
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, ErrStoreNotFound)
+	})
+
+	t.Run("Given Empty Store Name", func(t *testing.T) {
+		c, err := LoadStores(testStores(), "", mock.NewMockFilesys(ctrl), nil)
+		assert.Nil(t, c)
+		assert.Equal(t, ErrStoreNameEmpty, err)
+	})
+
+	t.Run("Where Layer Fails To Load", func(t *testing.T) {
+		testErr := errors.New("fs: error")
+
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(path.Join("team", configFilename)).Return(true, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(false, nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil)
+		fs.EXPECT().Read(path.Join("team", configFilename)).Return(nil, testErr)
+
+		c, err := LoadStores(testStores(), StorePersonal, fs, nil)
+		assert.Nil(t, c)
+		assert.ErrorIs(t, err, testErr)
+	})
+
+	t.Run("Migrates Read-Only Store Without Writing", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		fs.EXPECT().FileExists(path.Join("team", configFilename)).Return(false, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(true, nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil)
+		fs.EXPECT().Read(path.Join("system", configFilename)).Return([]byte("secrets:\n- name: Shared\n  value: system\n"), nil)
+		fs.EXPECT().Write(gomock.Any(), gomock.Any()).Times(0)
+
+		c, err := LoadStores(testStores(), StorePersonal, fs, nil)
+		assert.NoError(t, err)
+
+		s, err := c.GetSecret("Shared")
+		assert.NoError(t, err)
+		assert.Equal(t, "system", s.Value)
+	})
+}
+
+func TestConfig_Update_Stores(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	unlock := func() error { return nil }
+
+	newConfig := func(fs *mock.MockFilesys) *Config {
+		fs.EXPECT().FileExists(path.Join("team", configFilename)).Return(true, nil)
+		fs.EXPECT().FileExists(path.Join("system", configFilename)).Return(false, nil)
+		fs.EXPECT().Read(path.Join("personal", configFilename)).Return([]byte(testPersonalConfig), nil).Times(2)
+		fs.EXPECT().Read(path.Join("team", configFilename)).Return([]byte(testTeamConfig), nil).Times(2)
+		fs.EXPECT().Lock(path.Join("personal", configLockFilename)).Return(unlock, nil)
+
+		c, err := LoadStores(testStores(), StorePersonal, fs, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		return c
+	}
+
+	t.Run("Saves To Selected Store", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		c := newConfig(fs)
+		fs.EXPECT().FileExists(path.Join("personal", configFilename)).Return(false, nil)
+		fs.EXPECT().Write(path.Join("personal", configFilename), gomock.Any()).DoAndReturn(func(_ string, data []byte) error {
+			assert.Contains(t, string(data), "name: Shared\n      value: personal\n")
+			assert.NotContains(t, string(data), "registry.example.com")
+			return nil
+		})
+
+		err := c.Update(func(c *Config) error {
+			return c.AddSecret("Shared", "personal", false, nil)
+		})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Another Store Is Changed", func(t *testing.T) {
+		fs := mock.NewMockFilesys(ctrl)
+		c := newConfig(fs)
+
+		err := c.Update(func(c *Config) error {
+			w, _ := c.GetWorkspace("/c/api")
+			return w.AddScript("test", "go test ./...")
+		})
+		assert.ErrorIs(t, err, ErrStoreReadOnly)
+		assert.Contains(t, err.Error(), "--store team")
+	})
+}
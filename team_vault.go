@@ -0,0 +1,457 @@
+package passport
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+
+	"filippo.io/age"
+	"gopkg.in/yaml.v3"
+)
+
+// TeamVaultFilename is the name of a team vault file,
+// kept in the root of a workspace's directory.
+const TeamVaultFilename = ".passport-vault.yaml"
+
+// teamVaultLockFilename is the name of the file locked while a team
+// vault is changed, kept next to the vault.
+const teamVaultLockFilename = TeamVaultFilename + ".lock"
+
+const teamVaultKeySize = 32
+
+// Common team vault errors.
+var (
+	ErrTeamVaultNotFound       = errors.New("team vault: not found")
+	ErrTeamVaultExists         = errors.New("team vault: already exists")
+	ErrTeamVaultInvalid        = errors.New("team vault: invalid file")
+	ErrTeamVaultNotMember      = errors.New("team vault: you are not a member")
+	ErrTeamVaultNoIdentity     = errors.New("team vault: no identity to open the vault with")
+	ErrTeamVaultMemberExists   = errors.New("team vault: member already exists")
+	ErrTeamVaultMemberNameUsed = errors.New("team vault: member name is already used")
+	ErrTeamVaultMemberNotFound = errors.New("team vault: member not found")
+	ErrTeamVaultLastMember     = errors.New("team vault: the last member cannot be removed")
+)
+
+// TeamVault is a file of secrets, shared by the members of a team, which
+// can be committed to a repository. Each secret is encrypted once, with a
+// data key, which is encrypted to the public key of each member.
+type TeamVault struct {
+	path string
+	fs   Filesys
+
+	Members []*TeamVaultMember `yaml:"members"`
+	Secrets []*TeamVaultSecret `yaml:"secrets,omitempty"`
+}
+
+// TeamVaultMember is a member of a team vault, where Key is the vault's
+// data key, encrypted to the member's public key, in the age format.
+type TeamVaultMember struct {
+	Name      string `yaml:"name,omitempty"`
+	PublicKey string `yaml:"public_key"`
+	Key       string `yaml:"key"`
+}
+
+// TeamVaultSecret is a secret in a team vault, where Value is encrypted
+// using the vault's data key.
+type TeamVaultSecret struct {
+	Name  string `yaml:"name"`
+	Value string `yaml:"value"`
+}
+
+// NewTeamVault creates a team vault in dir, where id is its first member.
+// If a vault already exists in dir, ErrTeamVaultExists is returned.
+func NewTeamVault(dir string, fs Filesys, id *Identity, name string) (*TeamVault, error) {
+	filePath := path.Join(dir, TeamVaultFilename)
+	ok, err := fs.FileExists(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return nil, ErrTeamVaultExists
+	}
+
+	key := make([]byte, teamVaultKeySize)
+	_, err = rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &TeamVault{path: filePath, fs: fs}
+	err = v.addMember(key, id.PublicKey, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, v.Save()
+}
+
+// LoadTeamVault reads the team vault in dir. If there isn't
+// one, ErrTeamVaultNotFound is returned.
+func LoadTeamVault(dir string, fs Filesys) (*TeamVault, error) {
+	filePath := path.Join(dir, TeamVaultFilename)
+	ok, err := fs.FileExists(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, ErrTeamVaultNotFound
+	}
+
+	data, err := fs.Read(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &TeamVault{path: filePath, fs: fs}
+	err = yaml.Unmarshal(data, v)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrTeamVaultInvalid, err.Error())
+	}
+
+	return v, nil
+}
+
+// UpdateTeamVault safely modifies the team vault in dir, by holding a lock
+// on it while it's loaded, modified by fn and saved, as Config.Update does.
+// This prevents changes made by concurrent processes from being lost, which
+// could leave secrets unreadable, where a member's removal changed the data
+// key. If fn returns an error, the vault is not saved.
+func UpdateTeamVault(dir string, fs Filesys, fn func(v *TeamVault) error) error {
+	unlock, err := fs.Lock(path.Join(dir, teamVaultLockFilename))
+	if err != nil {
+		return err
+	}
+
+	defer unlock()
+
+	v, err := LoadTeamVault(dir, fs)
+	if err != nil {
+		return err
+	}
+
+	err = fn(v)
+	if err != nil {
+		return err
+	}
+
+	return v.Save()
+}
+
+// Save writes the vault to its file.
+func (v *TeamVault) Save() error {
+	data, _ := yaml.Marshal(v)
+
+	return v.fs.Write(v.path, data)
+}
+
+// member returns the member with the given public key, or name.
+func (v *TeamVault) member(key string) (int, *TeamVaultMember) {
+	for i, m := range v.Members {
+		if m.PublicKey == key || (m.Name != "" && m.Name == key) {
+			return i, m
+		}
+	}
+
+	return -1, nil
+}
+
+// dataKey decrypts the vault's data key, using the identity.
+func (v *TeamVault) dataKey(id *Identity) ([]byte, error) {
+	if id == nil {
+		return nil, ErrTeamVaultNoIdentity
+	}
+
+	_, m := v.member(id.PublicKey)
+	if m == nil {
+		return nil, ErrTeamVaultNotMember
+	}
+
+	data, err := base64.StdEncoding.DecodeString(m.Key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecryptFailed, err.Error())
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(data), id.x25519)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecryptFailed, err.Error())
+	}
+
+	return ioutil.ReadAll(r)
+}
+
+// addMember encrypts the data key, key, to the public key, and adds
+// the member to the vault.
+func (v *TeamVault) addMember(key []byte, publicKey, name string) error {
+	recipients, err := parseRecipients([]string{publicKey})
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(key)
+	if err == nil {
+		err = w.Close()
+	}
+
+	if err != nil {
+		return err
+	}
+
+	v.Members = append(v.Members, &TeamVaultMember{
+		Name:      name,
+		PublicKey: publicKey,
+		Key:       base64.StdEncoding.EncodeToString(buf.Bytes()),
+	})
+
+	return nil
+}
+
+// AddMember gives the owner of publicKey access to the vault, by encrypting
+// the data key to their public key. Only existing members, id, can add
+// members. As members can be removed by name, a name can't be used by more
+// than one member. Changes are not saved until Save is called.
+func (v *TeamVault) AddMember(id *Identity, publicKey, name string) error {
+	if _, m := v.member(publicKey); m != nil {
+		return ErrTeamVaultMemberExists
+	}
+
+	if _, m := v.member(name); name != "" && m != nil {
+		return fmt.Errorf("%w: %s", ErrTeamVaultMemberNameUsed, name)
+	}
+
+	key, err := v.dataKey(id)
+	if err != nil {
+		return err
+	}
+
+	return v.addMember(key, publicKey, name)
+}
+
+// RemoveMember removes the member with the given public key, or name. As
+// the member knows the data key, a new data key is generated, and each of
+// the secrets are encrypted again, using id to decrypt them.
+func (v *TeamVault) RemoveMember(id *Identity, member string) error {
+	i, m := v.member(member)
+	if m == nil {
+		return fmt.Errorf("%w: %s", ErrTeamVaultMemberNotFound, member)
+	}
+
+	if len(v.Members) == 1 {
+		return ErrTeamVaultLastMember
+	}
+
+	oldKey, err := v.dataKey(id)
+	if err != nil {
+		return err
+	}
+
+	members := append(v.Members[:i:i], v.Members[i+1:]...)
+	secrets := make([]*TeamVaultSecret, len(v.Secrets))
+
+	key := make([]byte, teamVaultKeySize)
+	_, err = rand.Read(key)
+	if err != nil {
+		return err
+	}
+
+	for i, s := range v.Secrets {
+		value, err := openTeamSecret(oldKey, s)
+		if err != nil {
+			return err
+		}
+
+		secrets[i], err = sealTeamSecret(key, s.Name, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	rotated := &TeamVault{path: v.path, fs: v.fs, Secrets: secrets}
+	for _, m := range members {
+		err = rotated.addMember(key, m.PublicKey, m.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	v.Members = rotated.Members
+	v.Secrets = rotated.Secrets
+
+	return nil
+}
+
+// SetSecret encrypts the value, and sets it as the value of the
+// secret with the given name, adding it if it doesn't exist.
+func (v *TeamVault) SetSecret(id *Identity, name, value string) error {
+	if name == "" {
+		return ErrSecretNameEmpty
+	}
+
+	if value == "" {
+		return ErrSecretValueEmpty
+	}
+
+	key, err := v.dataKey(id)
+	if err != nil {
+		return err
+	}
+
+	s, err := sealTeamSecret(key, name, value)
+	if err != nil {
+		return err
+	}
+
+	for i, existing := range v.Secrets {
+		if existing.Name == name {
+			v.Secrets[i] = s
+			return nil
+		}
+	}
+
+	v.Secrets = append(v.Secrets, s)
+
+	return nil
+}
+
+// GetSecret returns the plain-text value of the secret with the given name.
+func (v *TeamVault) GetSecret(id *Identity, name string) (string, error) {
+	for _, s := range v.Secrets {
+		if s.Name != name {
+			continue
+		}
+
+		key, err := v.dataKey(id)
+		if err != nil {
+			return "", err
+		}
+
+		return openTeamSecret(key, s)
+	}
+
+	return "", ErrSecretNotFound
+}
+
+// RemoveSecret removes the secret with the given name.
+func (v *TeamVault) RemoveSecret(name string) error {
+	for i, s := range v.Secrets {
+		if s.Name == name {
+			v.Secrets = append(v.Secrets[:i], v.Secrets[i+1:]...)
+			return nil
+		}
+	}
+
+	return ErrSecretNotFound
+}
+
+// sealTeamSecret encrypts value with key, using AES256-GCM. The name of
+// the secret is authenticated, so values can't be swapped between secrets.
+func sealTeamSecret(key []byte, name, value string) (*TeamVaultSecret, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	data := gcm.Seal(nonce, nonce, []byte(value), []byte(name))
+
+	return &TeamVaultSecret{
+		Name:  name,
+		Value: base64.StdEncoding.EncodeToString(data),
+	}, nil
+}
+
+// openTeamSecret decrypts the value of s, with key.
+func openTeamSecret(key []byte, s *TeamVaultSecret) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s.Value)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("%w: %s", ErrDecryptFailed, s.Name)
+	}
+
+	nonce, data := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	value, err := gcm.Open(nil, nonce, data, []byte(s.Name))
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrDecryptFailed, s.Name)
+	}
+
+	return string(value), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	c, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecryptFailed, err.Error())
+	}
+
+	return cipher.NewGCM(c)
+}
+
+// UseIdentity sets the identity used to open the team vaults of workspaces,
+// when resolving secrets.
+func (c *Config) UseIdentity(id *Identity) {
+	c.identity = id
+}
+
+// TeamVault returns the team vault in the workspace's directory. The vault
+// is only read once. If there isn't one, ErrTeamVaultNotFound is returned.
+func (w *Workspace) TeamVault() (*TeamVault, error) {
+	if w.vault != nil {
+		return w.vault, nil
+	}
+
+	if w.c == nil || w.c.fs == nil {
+		return nil, ErrTeamVaultNotFound
+	}
+
+	v, err := LoadTeamVault(w.Path, w.c.fs)
+	if err != nil {
+		return nil, err
+	}
+
+	w.vault = v
+
+	return v, nil
+}
+
+// teamSecret returns the value of the secret with the given name from the
+// team vault of the script's workspace. If there isn't a vault, or the
+// secret isn't in it, ErrSecretNotFound is returned.
+func (s *WorkspaceScript) teamSecret(name string) (string, error) {
+	if s.w == nil {
+		return "", ErrSecretNotFound
+	}
+
+	v, err := s.w.TeamVault()
+	if errors.Is(err, ErrTeamVaultNotFound) {
+		return "", ErrSecretNotFound
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return v.GetSecret(s.c.identity, name)
+}
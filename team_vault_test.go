@@ -0,0 +1,329 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTeamVault returns a new team vault, in a temporary directory,
+// where owner is the only member.
+func newTestTeamVault(t *testing.T, owner *Identity) *TeamVault {
+	v, err := NewTeamVault(t.TempDir(), NewFilesys(), owner, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v
+}
+
+func TestNewTeamVault(t *testing.T) {
+	t.Run("Where Vault Does Not Exist", func(t *testing.T) {
+		owner := newTestIdentity(t)
+		dir := t.TempDir()
+		fs := NewFilesys()
+
+		_, err := NewTeamVault(dir, fs, owner, "owner")
+		assert.NoError(t, err)
+
+		v, err := LoadTeamVault(dir, fs)
+		assert.NoError(t, err)
+		assert.Len(t, v.Members, 1)
+		assert.Equal(t, "owner", v.Members[0].Name)
+		assert.Equal(t, owner.PublicKey, v.Members[0].PublicKey)
+
+		_, err = v.dataKey(owner)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Where Vault Exists", func(t *testing.T) {
+		dir := t.TempDir()
+		fs := NewFilesys()
+		fs.Write(path.Join(dir, TeamVaultFilename), []byte("members: []\n"))
+
+		v, err := NewTeamVault(dir, fs, newTestIdentity(t), "")
+		assert.Nil(t, v)
+		assert.Equal(t, ErrTeamVaultExists, err)
+	})
+}
+
+func TestLoadTeamVault(t *testing.T) {
+	t.Run("Where Vault Does Not Exist", func(t *testing.T) {
+		v, err := LoadTeamVault(t.TempDir(), NewFilesys())
+		assert.Nil(t, v)
+		assert.Equal(t, ErrTeamVaultNotFound, err)
+	})
+
+	t.Run("Where Vault Is Invalid", func(t *testing.T) {
+		dir := t.TempDir()
+		fs := NewFilesys()
+		fs.Write(path.Join(dir, TeamVaultFilename), []byte("members: {"))
+
+		v, err := LoadTeamVault(dir, fs)
+		assert.Nil(t, v)
+		assert.True(t, errors.Is(err, ErrTeamVaultInvalid))
+	})
+}
+
+func TestUpdateTeamVault(t *testing.T) {
+	owner := newTestIdentity(t)
+	fs := NewFilesys()
+
+	t.Run("Saves Changes", func(t *testing.T) {
+		dir := t.TempDir()
+		NewTeamVault(dir, fs, owner, "owner")
+
+		err := UpdateTeamVault(dir, fs, func(v *TeamVault) error {
+			return v.SetSecret(owner, "MySecret", "one")
+		})
+		assert.NoError(t, err)
+
+		v, _ := LoadTeamVault(dir, fs)
+		value, err := v.GetSecret(owner, "MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "one", value)
+	})
+
+	t.Run("Where Fn Returns Error", func(t *testing.T) {
+		dir := t.TempDir()
+		NewTeamVault(dir, fs, owner, "owner")
+		testErr := errors.New("test error")
+
+		err := UpdateTeamVault(dir, fs, func(v *TeamVault) error {
+			v.SetSecret(owner, "MySecret", "one")
+			return testErr
+		})
+		assert.Equal(t, testErr, err)
+
+		v, _ := LoadTeamVault(dir, fs)
+		assert.Empty(t, v.Secrets)
+	})
+
+	t.Run("Where Vault Does Not Exist", func(t *testing.T) {
+		err := UpdateTeamVault(t.TempDir(), fs, func(v *TeamVault) error {
+			return nil
+		})
+		assert.Equal(t, ErrTeamVaultNotFound, err)
+	})
+
+	t.Run("Given Concurrent Updates", func(t *testing.T) {
+		dir := t.TempDir()
+		NewTeamVault(dir, fs, owner, "owner")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				UpdateTeamVault(dir, fs, func(v *TeamVault) error {
+					return v.SetSecret(owner, fmt.Sprintf("Secret%d", i), "value")
+				})
+			}(i)
+		}
+
+		wg.Wait()
+
+		v, _ := LoadTeamVault(dir, fs)
+		assert.Len(t, v.Secrets, 5)
+	})
+}
+
+func TestTeamVault_SetSecret(t *testing.T) {
+	owner := newTestIdentity(t)
+	v := newTestTeamVault(t, owner)
+
+	t.Run("Given Member", func(t *testing.T) {
+		err := v.SetSecret(owner, "MySecret", "one")
+		assert.NoError(t, err)
+		assert.Equal(t, "MySecret", v.Secrets[0].Name)
+		assert.NotContains(t, v.Secrets[0].Value, "one")
+
+		err = v.SetSecret(owner, "MySecret", "two")
+		assert.NoError(t, err)
+		assert.Len(t, v.Secrets, 1)
+
+		value, err := v.GetSecret(owner, "MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "two", value)
+	})
+
+	t.Run("Given Non-Member", func(t *testing.T) {
+		err := v.SetSecret(newTestIdentity(t), "Other", "one")
+		assert.Equal(t, ErrTeamVaultNotMember, err)
+	})
+
+	t.Run("Given No Identity", func(t *testing.T) {
+		err := v.SetSecret(nil, "Other", "one")
+		assert.Equal(t, ErrTeamVaultNoIdentity, err)
+	})
+
+	t.Run("Given Empty Value", func(t *testing.T) {
+		err := v.SetSecret(owner, "Other", "")
+		assert.Equal(t, ErrSecretValueEmpty, err)
+	})
+}
+
+func TestTeamVault_GetSecret(t *testing.T) {
+	owner := newTestIdentity(t)
+	v := newTestTeamVault(t, owner)
+	v.SetSecret(owner, "A", "one")
+	v.SetSecret(owner, "B", "two")
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		value, err := v.GetSecret(owner, "C")
+		assert.Equal(t, "", value)
+		assert.Equal(t, ErrSecretNotFound, err)
+	})
+
+	t.Run("Where Values Are Swapped", func(t *testing.T) {
+		a, b := v.Secrets[0].Value, v.Secrets[1].Value
+		v.Secrets[0].Value, v.Secrets[1].Value = b, a
+		defer func() {
+			v.Secrets[0].Value, v.Secrets[1].Value = a, b
+		}()
+
+		value, err := v.GetSecret(owner, "A")
+		assert.Equal(t, "", value)
+		assert.True(t, errors.Is(err, ErrDecryptFailed))
+	})
+}
+
+func TestTeamVault_AddMember(t *testing.T) {
+	owner := newTestIdentity(t)
+	member := newTestIdentity(t)
+	v := newTestTeamVault(t, owner)
+	v.SetSecret(owner, "MySecret", "one")
+
+	t.Run("Given New Member", func(t *testing.T) {
+		err := v.AddMember(owner, member.PublicKey, "member")
+		assert.NoError(t, err)
+
+		value, err := v.GetSecret(member, "MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "one", value)
+	})
+
+	t.Run("Given Existing Member", func(t *testing.T) {
+		err := v.AddMember(owner, member.PublicKey, "")
+		assert.Equal(t, ErrTeamVaultMemberExists, err)
+	})
+
+	t.Run("Given Existing Member Name", func(t *testing.T) {
+		err := v.AddMember(owner, newTestIdentity(t).PublicKey, "member")
+		assert.True(t, errors.Is(err, ErrTeamVaultMemberNameUsed))
+		assert.Len(t, v.Members, 2)
+	})
+
+	t.Run("Given Invalid Public Key", func(t *testing.T) {
+		err := v.AddMember(owner, "invalid", "")
+		assert.True(t, errors.Is(err, ErrRecipientInvalid))
+	})
+
+	t.Run("Where Identity Is Not A Member", func(t *testing.T) {
+		err := v.AddMember(newTestIdentity(t), newTestIdentity(t).PublicKey, "")
+		assert.Equal(t, ErrTeamVaultNotMember, err)
+	})
+}
+
+func TestTeamVault_RemoveMember(t *testing.T) {
+	owner := newTestIdentity(t)
+	member := newTestIdentity(t)
+
+	t.Run("Given Member", func(t *testing.T) {
+		v := newTestTeamVault(t, owner)
+		v.SetSecret(owner, "MySecret", "one")
+		v.AddMember(owner, member.PublicKey, "member")
+		value := v.Secrets[0].Value
+		key := v.Members[0].Key
+
+		err := v.RemoveMember(owner, "member")
+		assert.NoError(t, err)
+		assert.Len(t, v.Members, 1)
+		assert.Equal(t, owner.PublicKey, v.Members[0].PublicKey)
+		assert.NotEqual(t, key, v.Members[0].Key)
+		assert.NotEqual(t, value, v.Secrets[0].Value)
+
+		got, err := v.GetSecret(owner, "MySecret")
+		assert.NoError(t, err)
+		assert.Equal(t, "one", got)
+
+		_, err = v.GetSecret(member, "MySecret")
+		assert.Equal(t, ErrTeamVaultNotMember, err)
+	})
+
+	t.Run("Given Last Member", func(t *testing.T) {
+		v := newTestTeamVault(t, owner)
+
+		err := v.RemoveMember(owner, owner.PublicKey)
+		assert.Equal(t, ErrTeamVaultLastMember, err)
+	})
+
+	t.Run("Where Member Does Not Exist", func(t *testing.T) {
+		v := newTestTeamVault(t, owner)
+
+		err := v.RemoveMember(owner, "unknown")
+		assert.True(t, errors.Is(err, ErrTeamVaultMemberNotFound))
+	})
+}
+
+func TestTeamVault_RemoveSecret(t *testing.T) {
+	owner := newTestIdentity(t)
+	v := newTestTeamVault(t, owner)
+	v.SetSecret(owner, "MySecret", "one")
+
+	err := v.RemoveSecret("MySecret")
+	assert.NoError(t, err)
+	assert.Len(t, v.Secrets, 0)
+
+	err = v.RemoveSecret("MySecret")
+	assert.Equal(t, ErrSecretNotFound, err)
+}
+
+func TestWorkspaceScript_commandArgs_WithTeamVault(t *testing.T) {
+	owner := newTestIdentity(t)
+	dir := t.TempDir()
+	fs := NewFilesys()
+
+	v, _ := NewTeamVault(dir, fs, owner, "")
+	v.SetSecret(owner, "password", "team123")
+	v.SetSecret(owner, "token", "team-token")
+	v.Save()
+
+	c := &Config{
+		fs: fs,
+		Secrets: []*Secret{
+			{Name: "token", Value: "personal-token"},
+		},
+	}
+	w := &Workspace{c: c, Path: dir}
+	s := &WorkspaceScript{c: c, w: w, Command: "login <secrets.password> <secrets.token>"}
+
+	t.Run("Given Identity", func(t *testing.T) {
+		c.UseIdentity(owner)
+		defer c.UseIdentity(nil)
+
+		args, err := s.commandArgs(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"login", "team123", "personal-token"}, args)
+	})
+
+	t.Run("Given No Identity", func(t *testing.T) {
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrTeamVaultNoIdentity))
+	})
+
+	t.Run("Where Secret Is Not In Vault", func(t *testing.T) {
+		c.UseIdentity(owner)
+		defer c.UseIdentity(nil)
+
+		s := &WorkspaceScript{c: c, w: w, Command: "login <secrets.other>"}
+		args, err := s.commandArgs(nil, nil)
+		assert.Nil(t, args)
+		assert.True(t, errors.Is(err, ErrSecretNotFound))
+	})
+}
@@ -0,0 +1,37 @@
+package template
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+// filters maps the names of filters to their implementation.
+var filters = map[string]func(string) string{
+	"base64": func(v string) string {
+		return base64.StdEncoding.EncodeToString([]byte(v))
+	},
+	"quote": quote,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"url":   url.QueryEscape,
+}
+
+func applyFilters(v string, names []string) string {
+	for _, name := range names {
+		v = filters[name](v)
+	}
+
+	return v
+}
+
+// quote wraps v in single quotes, escaping any single quotes
+// within it, so it is treated as a single word by a POSIX shell.
+// As references are resolved after a command is split into
+// arguments, quote is only needed for text given to a shell,
+// i.e. sh -c "echo <secrets.NAME | quote>", otherwise the quotes
+// become part of the argument. It doesn't quote for cmd /c.
+func quote(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", `'\''`) + "'"
+}
@@ -0,0 +1,396 @@
+// Package template parses and executes the templates used to interpolate
+// secrets, variables, arguments and environment variables into commands.
+//
+// A reference takes the form <kind.NAME>, where kind is one of secrets,
+// vars, args or env. References, other than secrets, can be given a
+// default value using <kind.NAME:-default>, where the default can't contain
+// a ">", as it ends the reference, and any reference can be
+// passed through filters, i.e. <secrets.NAME | base64 | quote>. A literal
+// "<" can be written as "<<", though text which doesn't start with one of
+// the kinds, i.e. "cat <input.txt", is kept as literal text.
+package template
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Common template errors.
+var (
+	ErrUnterminated      = errors.New("unterminated reference")
+	ErrNameEmpty         = errors.New("reference name is empty")
+	ErrNameInvalid       = errors.New("reference name is invalid")
+	ErrUnknownFilter     = errors.New("unknown filter")
+	ErrDefaultNotAllowed = errors.New("default values are not allowed for secrets")
+	ErrNulByte           = errors.New("text contains a NUL byte")
+)
+
+// Kind is the type of value a reference refers to.
+type Kind int
+
+// Supported reference kinds.
+const (
+	Secret Kind = iota
+	Var
+	Arg
+	Env
+)
+
+var kindNames = []string{
+	Secret: "secrets",
+	Var:    "vars",
+	Arg:    "args",
+	Env:    "env",
+}
+
+// String returns the name of the kind, as used in templates.
+func (k Kind) String() string {
+	if int(k) < 0 || int(k) >= len(kindNames) {
+		return fmt.Sprintf("Kind(%d)", int(k))
+	}
+
+	return kindNames[k]
+}
+
+func parseKind(name string) (Kind, bool) {
+	for k, v := range kindNames {
+		if v == name {
+			return Kind(k), true
+		}
+	}
+
+	return 0, false
+}
+
+// Ref is a typed reference to a value in a template.
+type Ref struct {
+	Kind Kind
+	Name string
+
+	// Default is the value used when the referenced value is empty
+	// or not set, where HasDefault is true.
+	Default    string
+	HasDefault bool
+
+	// Filters is a list of the names of filters, applied in order.
+	Filters []string
+
+	// Col is the 1-based column of the reference in the template.
+	Col int
+}
+
+// String returns the reference as it would be written in a template.
+func (r *Ref) String() string {
+	var sb strings.Builder
+	sb.WriteString("<" + r.Kind.String() + "." + r.Name)
+
+	if r.HasDefault {
+		sb.WriteString(":-" + r.Default)
+	}
+
+	for _, f := range r.Filters {
+		sb.WriteString(" | " + f)
+	}
+
+	sb.WriteString(">")
+
+	return sb.String()
+}
+
+// Error is an error found at a position in a template.
+type Error struct {
+	// Col is the 1-based column the error occurred at.
+	Col int
+	Err error
+}
+
+// Error returns the error message, including the column.
+func (e *Error) Error() string {
+	return fmt.Sprintf("template: col %d: %v", e.Col, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// ErrorList is a list of errors found when parsing a template.
+type ErrorList []*Error
+
+// Error returns each of the errors' messages, separated by a new line.
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the errors in the list match target.
+func (l ErrorList) Is(target error) bool {
+	for _, e := range l {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// node is a part of a template, either literal text or a reference.
+type node struct {
+	text string
+	ref  *Ref
+}
+
+// Template is a parsed template.
+type Template struct {
+	text  string
+	nodes []node
+}
+
+// Parse parses text into a template. If the text contains any invalid
+// references, an ErrorList will be returned, containing all errors.
+func Parse(text string) (*Template, error) {
+	t := &Template{text: text}
+	var errs ErrorList
+	var lit strings.Builder
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		if c != '<' {
+			lit.WriteByte(c)
+			i++
+			continue
+		}
+
+		if i+1 < len(text) && text[i+1] == '<' {
+			lit.WriteByte('<')
+			i += 2
+			continue
+		}
+
+		j := i + 1
+		for j < len(text) && isKindChar(text[j]) {
+			j++
+		}
+
+		// Only text in the form <kind. is treated as a reference, where
+		// kind is a known kind, everything else is kept as literal text.
+		kind, ok := parseKind(text[i+1 : j])
+		if !ok || j >= len(text) || text[j] != '.' {
+			lit.WriteByte(c)
+			i++
+			continue
+		}
+
+		col := column(text, i)
+		end := strings.IndexByte(text[j:], '>')
+		if end < 0 {
+			errs = append(errs, &Error{Col: col, Err: ErrUnterminated})
+			break
+		}
+
+		ref, refErrs := parseRef(kind, text[j+1:j+end], col)
+		errs = append(errs, refErrs...)
+
+		if lit.Len() > 0 {
+			t.nodes = append(t.nodes, node{text: lit.String()})
+			lit.Reset()
+		}
+
+		t.nodes = append(t.nodes, node{ref: ref})
+		i = j + end + 1
+	}
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if lit.Len() > 0 {
+		t.nodes = append(t.nodes, node{text: lit.String()})
+	}
+
+	return t, nil
+}
+
+// parseRef parses the body of a reference, i.e. NAME:-default | filter,
+// of the given kind.
+func parseRef(kind Kind, body string, col int) (*Ref, ErrorList) {
+	var errs ErrorList
+	ref := &Ref{Kind: kind, Col: col}
+
+	parts := strings.Split(body, "|")
+	name := strings.TrimSpace(parts[0])
+	if i := strings.Index(name, ":-"); i >= 0 {
+		ref.Default = strings.TrimSpace(name[i+2:])
+		ref.HasDefault = true
+		name = name[:i]
+
+		if kind == Secret {
+			errs = append(errs, &Error{Col: col, Err: ErrDefaultNotAllowed})
+		}
+	}
+
+	ref.Name = name
+
+	if name == "" {
+		errs = append(errs, &Error{Col: col, Err: ErrNameEmpty})
+	} else if !validName(name) {
+		errs = append(errs, &Error{Col: col, Err: fmt.Errorf("%w: %q", ErrNameInvalid, name)})
+	}
+
+	for _, f := range parts[1:] {
+		f = strings.TrimSpace(f)
+		if _, ok := filters[f]; !ok {
+			errs = append(errs, &Error{Col: col, Err: fmt.Errorf("%w: %q", ErrUnknownFilter, f)})
+		}
+
+		ref.Filters = append(ref.Filters, f)
+	}
+
+	return ref, errs
+}
+
+// ResolveFunc is a function used to resolve the value of a reference.
+type ResolveFunc func(ref *Ref) (string, error)
+
+// Execute renders the template, using fn to resolve the value of each
+// reference, before applying the reference's filters. If fn returns an
+// error, it is returned as an *Error, with the column of the reference.
+func (t *Template) Execute(fn ResolveFunc) (string, error) {
+	var sb strings.Builder
+
+	for _, n := range t.nodes {
+		if n.ref == nil {
+			sb.WriteString(n.text)
+			continue
+		}
+
+		v, err := fn(n.ref)
+		if err != nil {
+			return "", &Error{Col: n.ref.Col, Err: err}
+		}
+
+		sb.WriteString(applyFilters(v, n.ref.Filters))
+	}
+
+	return sb.String(), nil
+}
+
+// SplitFunc is a function used to split text into arguments.
+type SplitFunc func(text string) ([]string, error)
+
+// placeholder is used to mark the position of references in text
+// given to a SplitFunc. It is not expected to be altered by splitting.
+const placeholder = "\x00"
+
+// ExecuteArgs splits the template into arguments using split, before
+// resolving the references within each argument using fn. As values are
+// resolved after splitting, they are delivered intact within the argument
+// they were referenced in, regardless of any spaces, quotes or escape
+// characters they contain.
+func (t *Template) ExecuteArgs(split SplitFunc, fn ResolveFunc) ([]string, error) {
+	var sb strings.Builder
+	var refs []*Ref
+
+	for _, n := range t.nodes {
+		if n.ref == nil {
+			if strings.Contains(n.text, placeholder) {
+				return nil, ErrNulByte
+			}
+
+			sb.WriteString(n.text)
+			continue
+		}
+
+		sb.WriteString(placeholder + strconv.Itoa(len(refs)) + placeholder)
+		refs = append(refs, n.ref)
+	}
+
+	words, err := split(sb.String())
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[int]string)
+	args := make([]string, len(words))
+
+	for i, w := range words {
+		parts := strings.Split(w, placeholder)
+		var arg strings.Builder
+
+		for j, part := range parts {
+			if j%2 == 0 {
+				arg.WriteString(part)
+				continue
+			}
+
+			idx, _ := strconv.Atoi(part)
+			v, ok := values[idx]
+			if !ok {
+				ref := refs[idx]
+				v, err = fn(ref)
+				if err != nil {
+					return nil, &Error{Col: ref.Col, Err: err}
+				}
+
+				v = applyFilters(v, ref.Filters)
+				values[idx] = v
+			}
+
+			arg.WriteString(v)
+		}
+
+		args[i] = arg.String()
+	}
+
+	return args, nil
+}
+
+// Refs returns all references in the template, in order.
+func (t *Template) Refs() []*Ref {
+	var refs []*Ref
+	for _, n := range t.nodes {
+		if n.ref != nil {
+			refs = append(refs, n.ref)
+		}
+	}
+
+	return refs
+}
+
+// String returns the text the template was parsed from.
+func (t *Template) String() string {
+	return t.text
+}
+
+// ValidName reports whether name can be used in a reference, i.e. it isn't
+// empty, and only contains letters, digits, '-' and '_'.
+func ValidName(name string) bool {
+	return name != "" && validName(name)
+}
+
+func isKindChar(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func validName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// column returns the 1-based column of the byte offset i in text.
+func column(text string, i int) int {
+	return utf8.RuneCountInString(text[:i]) + 1
+}
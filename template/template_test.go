@@ -0,0 +1,240 @@
+package template
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Given Valid References", func(t *testing.T) {
+		tmpl, err := Parse("docker push <vars.registry:-docker.io>/app:<args.1 | lower> --password <secrets.pass|base64| quote>")
+		assert.NoError(t, err)
+
+		refs := tmpl.Refs()
+		assert.Equal(t, []*Ref{
+			{Kind: Var, Name: "registry", Default: "docker.io", HasDefault: true, Col: 13},
+			{Kind: Arg, Name: "1", Filters: []string{"lower"}, Col: 44},
+			{Kind: Secret, Name: "pass", Filters: []string{"base64", "quote"}, Col: 72},
+		}, refs)
+	})
+
+	t.Run("Given Default Containing Angle Bracket", func(t *testing.T) {
+		tmpl, err := Parse("echo <args.1:-a>b>")
+		assert.NoError(t, err)
+		assert.Equal(t, []*Ref{{Kind: Arg, Name: "1", Default: "a", HasDefault: true, Col: 6}}, tmpl.Refs())
+
+		out, err := tmpl.Execute(func(ref *Ref) (string, error) { return ref.Default, nil })
+		assert.NoError(t, err)
+		assert.Equal(t, "echo ab>", out)
+	})
+
+	t.Run("Given Literal Text", func(t *testing.T) {
+		tests := []struct {
+			name string
+			text string
+			want string
+		}{
+			{"Empty", "", ""},
+			{"No References", "echo hello", "echo hello"},
+			{"Escaped Reference", "echo <<secrets.name>", "echo <secrets.name>"},
+			{"Lone Angle Bracket", "echo a < b", "echo a < b"},
+			{"Angle Bracket Without Kind", "echo <name> <Vars.x>", "echo <name> <Vars.x>"},
+			{"Unknown Kind", "cat <input.txt <secret.name> <foo.a", "cat <input.txt <secret.name> <foo.a"},
+			{"Trailing Angle Bracket", "echo <", "echo <"},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tmpl, err := Parse(tt.text)
+				assert.NoError(t, err)
+				assert.Empty(t, tmpl.Refs())
+
+				out, err := tmpl.Execute(nil)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.want, out)
+			})
+		}
+	})
+
+	t.Run("Given Invalid References", func(t *testing.T) {
+		tests := []struct {
+			name string
+			text string
+			errs []error
+			cols []int
+		}{
+			{"Empty Name", "echo <vars.>", []error{ErrNameEmpty}, []int{6}},
+			{"Invalid Name", "echo <vars.a b>", []error{ErrNameInvalid}, []int{6}},
+			{"Unknown Filter", "echo <vars.a | rot13>", []error{ErrUnknownFilter}, []int{6}},
+			{"Secret Default", "echo <secrets.a:-b>", []error{ErrDefaultNotAllowed}, []int{6}},
+			{"Unterminated", "echo <vars.a", []error{ErrUnterminated}, []int{6}},
+			{"Multiple Errors", "<vars.> <vars.b | x> <env.", []error{ErrNameEmpty, ErrUnknownFilter, ErrUnterminated}, []int{1, 9, 22}},
+			{"Multi-Byte Column", "echo ✓ <vars.a b>", []error{ErrNameInvalid}, []int{8}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				tmpl, err := Parse(tt.text)
+				assert.Nil(t, tmpl)
+
+				var errs ErrorList
+				assert.True(t, errors.As(err, &errs))
+				assert.Equal(t, len(tt.errs), len(errs))
+
+				for i, e := range errs {
+					assert.ErrorIs(t, e, tt.errs[i])
+					assert.Equal(t, tt.cols[i], e.Col)
+				}
+			})
+		}
+	})
+}
+
+func TestTemplate_Execute(t *testing.T) {
+	values := map[string]string{
+		"name":  "Reece",
+		"quote": "it's",
+		"space": "  padded  ",
+	}
+	resolve := func(ref *Ref) (string, error) {
+		v, ok := values[ref.Name]
+		if !ok {
+			return "", errors.New("not found")
+		}
+
+		return v, nil
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"Plain Reference", "hello <vars.name>!", "hello Reece!"},
+		{"Base64 Filter", "<secrets.name | base64>", "UmVlY2U="},
+		{"Quote Filter", "<secrets.quote | quote>", `'it'\''s'`},
+		{"Upper Filter", "<vars.name | upper>", "REECE"},
+		{"Lower Filter", "<vars.name | lower>", "reece"},
+		{"Trim Filter", "[<vars.space | trim>]", "[padded]"},
+		{"URL Filter", "<vars.quote | url>", "it%27s"},
+		{"Chained Filters", "<vars.name | upper | base64>", "UkVFQ0U="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := Parse(tt.text)
+			assert.NoError(t, err)
+
+			out, err := tmpl.Execute(resolve)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out)
+		})
+	}
+
+	t.Run("Where Resolve Fails", func(t *testing.T) {
+		tmpl, err := Parse("echo <vars.missing>")
+		assert.NoError(t, err)
+
+		out, err := tmpl.Execute(resolve)
+		assert.Equal(t, "", out)
+		assert.EqualError(t, err, "template: col 6: not found")
+	})
+}
+
+func TestTemplate_ExecuteArgs(t *testing.T) {
+	values := map[string]string{
+		"space":  "hello world",
+		"quotes": `"it's"`,
+		"slash":  `a\b`,
+		"empty":  "",
+	}
+	resolve := func(ref *Ref) (string, error) {
+		v, ok := values[ref.Name]
+		if !ok {
+			return "", errors.New("not found")
+		}
+
+		return v, nil
+	}
+	split := func(text string) ([]string, error) {
+		return strings.Fields(text), nil
+	}
+
+	t.Run("Given Values With Special Characters", func(t *testing.T) {
+		tmpl, err := Parse("run <vars.space> --q=<vars.quotes> <vars.slash><vars.space> <vars.empty>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(split, resolve)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"run", "hello world", `--q="it's"`, `a\bhello world`, ""}, args)
+	})
+
+	t.Run("Given Quote Filter", func(t *testing.T) {
+		// The quotes are kept, for the shell the argument is given to.
+		tmpl, err := Parse("sh -c echo<vars.space | quote>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(split, resolve)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"sh", "-c", "echo'hello world'"}, args)
+	})
+
+	t.Run("Resolves Each Reference Once", func(t *testing.T) {
+		calls := 0
+		tmpl, err := Parse("<vars.space> <vars.space | upper>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(split, func(ref *Ref) (string, error) {
+			calls++
+			return resolve(ref)
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hello world", "HELLO WORLD"}, args)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Where Split Fails", func(t *testing.T) {
+		testErr := errors.New("split: test error")
+		tmpl, err := Parse("run <vars.space>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(func(string) ([]string, error) {
+			return nil, testErr
+		}, resolve)
+		assert.Nil(t, args)
+		assert.Equal(t, testErr, err)
+	})
+
+	t.Run("Where Resolve Fails", func(t *testing.T) {
+		tmpl, err := Parse("run <vars.missing>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(split, resolve)
+		assert.Nil(t, args)
+		assert.EqualError(t, err, "template: col 5: not found")
+	})
+
+	t.Run("Given Text With NUL Byte", func(t *testing.T) {
+		tmpl, err := Parse("run \x00 <vars.space>")
+		assert.NoError(t, err)
+
+		args, err := tmpl.ExecuteArgs(split, resolve)
+		assert.Nil(t, args)
+		assert.Equal(t, ErrNulByte, err)
+	})
+}
+
+func TestValidName(t *testing.T) {
+	assert.True(t, ValidName("API_KEY-2"))
+	assert.False(t, ValidName(""))
+	assert.False(t, ValidName("api.key"))
+	assert.False(t, ValidName("API KEY"))
+}
+
+func TestRef_String(t *testing.T) {
+	ref := &Ref{Kind: Env, Name: "HOME", Default: "/", HasDefault: true, Filters: []string{"quote"}}
+	assert.Equal(t, "<env.HOME:-/ | quote>", ref.String())
+}
@@ -0,0 +1,226 @@
+package passport
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrConfigEntryEmpty is returned when validating a config, where an entry in
+// a list is empty, i.e. a "-" without a value, left when editing it by hand.
+var ErrConfigEntryEmpty = errors.New("config: list entry cannot be empty")
+
+// ValidationError is an error found when validating a config, with
+// the path to the offending value, i.e. workspaces[0].scripts[1].
+type ValidationError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the path and message of the error.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors is a list of errors found when validating a config.
+type ValidationErrors []*ValidationError
+
+// Error returns each of the errors' messages, separated by a new line.
+func (l ValidationErrors) Error() string {
+	msgs := make([]string, len(l))
+	for i, e := range l {
+		msgs[i] = e.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Is reports whether any of the errors in the list match target.
+func (l ValidationErrors) Is(target error) bool {
+	for _, e := range l {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Validate checks the config for values which could not have been added
+// through the config's functions, such as duplicate or empty names, which
+// are usually the result of the config file being edited by hand. If the
+// config is invalid, all errors found are returned as ValidationErrors.
+//
+// The commands of scripts are not parsed, as they are validated when run,
+// or by calling WorkspaceScript.Validate.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+	add := func(err error, format string, a ...interface{}) {
+		errs = append(errs, &ValidationError{Path: fmt.Sprintf(format, a...), Err: err})
+	}
+
+	errs = append(errs, validateSecrets(c.Secrets, "secrets")...)
+
+	workspaceNames := make(map[string]bool)
+	workspacePaths := make(map[string]bool)
+	for i, w := range c.Workspaces {
+		if w == nil {
+			add(ErrConfigEntryEmpty, "workspaces[%d]", i)
+			continue
+		}
+
+		switch {
+		case w.Name == "":
+			add(ErrWorkspaceNameEmpty, "workspaces[%d]", i)
+		case workspaceNames[w.Name]:
+			add(ErrWorkspaceNameExists, "workspaces[%d]", i)
+		}
+
+		switch {
+		case w.Path == "":
+			add(ErrWorkspacePathEmpty, "workspaces[%d]", i)
+		case workspacePaths[w.Path]:
+			add(ErrWorkspacePathExists, "workspaces[%d]", i)
+		}
+
+		workspaceNames[w.Name] = true
+		workspacePaths[w.Path] = true
+
+		scriptNames := make(map[string]bool)
+		for j, s := range w.Scripts {
+			if s == nil {
+				add(ErrConfigEntryEmpty, "workspaces[%d].scripts[%d]", i, j)
+				continue
+			}
+
+			switch {
+			case s.Name == "":
+				add(ErrWorkspaceScriptNameEmpty, "workspaces[%d].scripts[%d]", i, j)
+			case scriptNames[s.Name]:
+				add(ErrWorkspaceScriptNameExists, "workspaces[%d].scripts[%d]", i, j)
+			}
+
+			if strings.TrimSpace(s.Command) == "" {
+				add(ErrWorkspaceScriptCommandEmpty, "workspaces[%d].scripts[%d]", i, j)
+			}
+
+			scriptNames[s.Name] = true
+		}
+
+		errs = append(errs, validateSecrets(w.Secrets, fmt.Sprintf("workspaces[%d].secrets", i))...)
+	}
+
+	if c.ExpiryWarning != "" {
+		if _, err := ParseDuration(c.ExpiryWarning); err != nil {
+			add(ErrExpiryWarningInvalid, "expiry_warning")
+		}
+	}
+
+	if c.Vault != nil && strings.Trim(c.Vault.Path, "/") == "" {
+		add(ErrVaultPathEmpty, "vault")
+	}
+
+	errs = append(errs, validateProfiles(c.Profiles, "profiles")...)
+	for i, w := range c.Workspaces {
+		if w == nil {
+			continue
+		}
+
+		errs = append(errs, validateProfiles(w.Profiles, fmt.Sprintf("workspaces[%d].profiles", i))...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateSecrets(secrets []*Secret, path string) ValidationErrors {
+	var errs ValidationErrors
+	add := func(err error, i int) {
+		errs = append(errs, &ValidationError{Path: fmt.Sprintf("%s[%d]", path, i), Err: err})
+	}
+
+	names := make(map[string]bool)
+	for i, s := range secrets {
+		if s == nil {
+			add(ErrConfigEntryEmpty, i)
+			continue
+		}
+
+		switch {
+		case s.Name == "":
+			add(ErrSecretNameEmpty, i)
+		case names[s.Name]:
+			add(ErrSecretAlreadyExists, i)
+		}
+
+		if s.Source != "" {
+			if err := validateSecretSource(s.Source); err != nil {
+				add(err, i)
+			}
+
+			if _, err := s.timeout(); err != nil {
+				add(err, i)
+			}
+		} else if s.Value == "" {
+			add(ErrSecretValueEmpty, i)
+		}
+
+		if s.RotateEvery != "" {
+			if _, err := ParseDuration(s.RotateEvery); err != nil {
+				add(ErrSecretRotateInvalid, i)
+			}
+		}
+
+		for j, v := range s.History {
+			if v == nil {
+				errs = append(errs, &ValidationError{Path: fmt.Sprintf("%s[%d].history[%d]", path, i, j), Err: ErrConfigEntryEmpty})
+			}
+		}
+
+		for _, a := range s.Allow {
+			if a == nil || a.Workspace == "" {
+				add(ErrSecretAccessWorkspaceEmpty, i)
+				break
+			}
+		}
+
+		names[s.Name] = true
+	}
+
+	return errs
+}
+
+func validateProfiles(set ProfileSet, path string) ValidationErrors {
+	var errs ValidationErrors
+	names := make(map[string]bool)
+
+	for i, p := range set {
+		var err error
+		switch {
+		case p == nil:
+			err = ErrConfigEntryEmpty
+		case p.Name == "":
+			err = ErrProfileNameEmpty
+		case names[p.Name]:
+			err = ErrProfileNameExists
+		}
+
+		if err != nil {
+			errs = append(errs, &ValidationError{Path: fmt.Sprintf("%s[%d]", path, i), Err: err})
+		}
+
+		if p != nil {
+			names[p.Name] = true
+		}
+	}
+
+	return errs
+}
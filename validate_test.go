@@ -0,0 +1,148 @@
+package passport
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestConfig_Validate(t *testing.T) {
+	t.Run("Given Valid Config", func(t *testing.T) {
+		cnf := &Config{
+			Secrets: []*Secret{
+				{Name: "a", Value: "1"},
+				{Name: "b", Value: "2"},
+				{Name: "c", Source: "file:~/token", Timeout: "10s"},
+				{Name: "d", Value: "4", Allow: []*SecretAccess{{Workspace: "app", Script: "build"}}, Confirm: true},
+			},
+			Workspaces: []*Workspace{
+				{
+					Name: "app",
+					Path: "/c/app",
+					Scripts: []*WorkspaceScript{
+						{Name: "build", Command: "go build"},
+						{Name: "test", Command: "go test"},
+					},
+					Profiles: ProfileSet{{Name: "dev"}},
+					Secrets:  []*Secret{{Name: "a", Value: "3"}},
+				},
+				{Name: "api", Path: "/c/api"},
+			},
+			Profiles: ProfileSet{{Name: "dev"}, {Name: "prod"}},
+		}
+
+		err := cnf.Validate()
+		assert.NoError(t, err)
+	})
+
+	t.Run("Given Invalid Config", func(t *testing.T) {
+		cnf := &Config{
+			Secrets: []*Secret{
+				{Name: "a", Value: "1"},
+				{Name: "", Value: "2"},
+				{Name: "a", Value: ""},
+				{Name: "c", Source: "ftp:token"},
+				{Name: "d", Source: "exec:whoami", Timeout: "soon"},
+				{Name: "e", Value: "5", RotateEvery: "often"},
+				{Name: "f", Value: "6", Allow: []*SecretAccess{{Workspace: "app"}, {Script: "build"}}},
+			},
+			Workspaces: []*Workspace{
+				{
+					Name: "app",
+					Path: "/c/app",
+					Scripts: []*WorkspaceScript{
+						{Name: "build", Command: "go build"},
+						{Name: "build", Command: " "},
+						{Name: "", Command: "go test"},
+					},
+					Profiles: ProfileSet{{Name: "dev"}, {Name: "dev"}},
+					Secrets:  []*Secret{{Name: "a", Value: "1"}, {Name: "a", Value: "2"}},
+				},
+				{Name: "app", Path: ""},
+				{Name: "", Path: "/c/app"},
+			},
+			Profiles: ProfileSet{{Name: ""}},
+		}
+
+		err := cnf.Validate()
+
+		var errs ValidationErrors
+		assert.True(t, errors.As(err, &errs))
+
+		expected := []struct {
+			path string
+			err  error
+		}{
+			{"secrets[1]", ErrSecretNameEmpty},
+			{"secrets[2]", ErrSecretAlreadyExists},
+			{"secrets[2]", ErrSecretValueEmpty},
+			{"secrets[3]", ErrSecretSourceInvalid},
+			{"secrets[4]", ErrSecretTimeoutInvalid},
+			{"secrets[5]", ErrSecretRotateInvalid},
+			{"secrets[6]", ErrSecretAccessWorkspaceEmpty},
+			{"workspaces[0].scripts[1]", ErrWorkspaceScriptNameExists},
+			{"workspaces[0].scripts[1]", ErrWorkspaceScriptCommandEmpty},
+			{"workspaces[0].scripts[2]", ErrWorkspaceScriptNameEmpty},
+			{"workspaces[0].secrets[1]", ErrSecretAlreadyExists},
+			{"workspaces[1]", ErrWorkspaceNameExists},
+			{"workspaces[1]", ErrWorkspacePathEmpty},
+			{"workspaces[2]", ErrWorkspaceNameEmpty},
+			{"workspaces[2]", ErrWorkspacePathExists},
+			{"profiles[0]", ErrProfileNameEmpty},
+			{"workspaces[0].profiles[1]", ErrProfileNameExists},
+		}
+
+		assert.Equal(t, len(expected), len(errs))
+		for i, e := range expected {
+			assert.Equal(t, e.path, errs[i].Path)
+			assert.Equal(t, e.err, errs[i].Err)
+		}
+
+		assert.ErrorIs(t, err, ErrWorkspacePathExists)
+		assert.Contains(t, err.Error(), "workspaces[2]: workspace: path already exists")
+	})
+}
+
+func TestConfig_Validate_EmptyEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		path string
+	}{
+		{"Secrets", "secrets:\n- \n", "secrets[0]"},
+		{"Secret History", "secrets:\n- name: a\n  value: \"1\"\n  history:\n  - \n", "secrets[0].history[0]"},
+		{"Workspaces", "workspaces:\n- \n", "workspaces[0]"},
+		{"Scripts", "workspaces:\n- name: app\n  path: /c/app\n  scripts:\n  - \n", "workspaces[0].scripts[0]"},
+		{"Workspace Secrets", "workspaces:\n- name: app\n  path: /c/app\n  secrets:\n  - \n", "workspaces[0].secrets[0]"},
+		{"Profiles", "profiles:\n- \n", "profiles[0]"},
+		{"Workspace Profiles", "workspaces:\n- name: app\n  path: /c/app\n  profiles:\n  - \n", "workspaces[0].profiles[0]"},
+	}
+
+	for _, tt := range tests {
+		t.Run("Given Empty "+tt.name+" Entry", func(t *testing.T) {
+			var cnf Config
+			assert.NoError(t, yaml.Unmarshal([]byte(tt.data), &cnf))
+
+			err := cnf.Validate()
+			var errs ValidationErrors
+			if assert.True(t, errors.As(err, &errs)) && assert.Len(t, errs, 1) {
+				assert.Equal(t, tt.path, errs[0].Path)
+				assert.Equal(t, ErrConfigEntryEmpty, errs[0].Err)
+			}
+		})
+	}
+}
+
+func TestWorkspaceScript_Validate(t *testing.T) {
+	t.Run("Given Valid Command", func(t *testing.T) {
+		s := &WorkspaceScript{Command: "echo <secrets.name>"}
+		assert.NoError(t, s.Validate())
+	})
+
+	t.Run("Given Invalid Command", func(t *testing.T) {
+		s := &WorkspaceScript{Command: "echo <secrets.>"}
+		assert.Error(t, s.Validate())
+	})
+}
@@ -0,0 +1,111 @@
+package passport
+
+import (
+	"errors"
+	"sort"
+)
+
+// Common variable errors.
+var (
+	ErrVarNameEmpty     = errors.New("var: name is empty")
+	ErrVarNotFound      = errors.New("var: not found")
+	ErrVarAlreadyExists = errors.New("var: already exists")
+
+	ErrEnvNotFound = errors.New("env: not found")
+	ErrArgNotFound = errors.New("arg: not found")
+)
+
+// VarSet is a set of plain-text variables, mapping variable names to
+// values. Unlike secrets, variables are never encrypted.
+type VarSet map[string]string
+
+// Add adds a new variable to the set. If a variable with
+// the same name already exists, ErrVarAlreadyExists is returned.
+func (vs *VarSet) Add(name, value string) error {
+	if name == "" {
+		return ErrVarNameEmpty
+	}
+
+	if _, ok := (*vs)[name]; ok {
+		return ErrVarAlreadyExists
+	}
+
+	vs.set(name, value)
+
+	return nil
+}
+
+// Get returns the value of the variable with the given name. If
+// the variable does not exist, ErrVarNotFound is returned.
+func (vs VarSet) Get(name string) (string, error) {
+	if name == "" {
+		return "", ErrVarNameEmpty
+	}
+
+	v, ok := vs[name]
+	if !ok {
+		return "", ErrVarNotFound
+	}
+
+	return v, nil
+}
+
+// Remove removes the variable with the given name from the set. If
+// the variable does not exist, ErrVarNotFound is returned.
+func (vs VarSet) Remove(name string) error {
+	if name == "" {
+		return ErrVarNameEmpty
+	}
+
+	if _, ok := vs[name]; !ok {
+		return ErrVarNotFound
+	}
+
+	delete(vs, name)
+
+	return nil
+}
+
+// Names returns the names of the variables in the set, in order.
+func (vs VarSet) Names() []string {
+	names := make([]string, 0, len(vs))
+	for k := range vs {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func (vs *VarSet) set(name, value string) {
+	if *vs == nil {
+		*vs = make(VarSet)
+	}
+
+	(*vs)[name] = value
+}
+
+// lookupVar resolves the value of the variable, name, for the script.
+// Variables are resolved from the active profile first, then the
+// script, its workspace and finally the global config of each store.
+func (s *WorkspaceScript) lookupVar(p *Profile, name string) (string, bool) {
+	sets := []VarSet{p.Vars, s.Vars}
+	if s.w != nil {
+		sets = append(sets, s.w.Vars)
+	}
+
+	if s.c != nil {
+		for _, l := range s.c.stack() {
+			sets = append(sets, l.Vars)
+		}
+	}
+
+	for _, vs := range sets {
+		if v, ok := vs[name]; ok {
+			return v, true
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,164 @@
+package passport
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+func TestVarSet_Add(t *testing.T) {
+	var vs VarSet
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		err := vs.Add("REGION", "eu-west-1")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", vs["REGION"])
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := vs.Add("", "eu-west-1")
+		assert.Equal(t, ErrVarNameEmpty, err)
+	})
+
+	t.Run("Where Var Already Exists", func(t *testing.T) {
+		err := vs.Add("REGION", "eu-west-2")
+		assert.Equal(t, ErrVarAlreadyExists, err)
+	})
+}
+
+func TestVarSet_Get(t *testing.T) {
+	vs := VarSet{"REGION": "eu-west-1"}
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		v, err := vs.Get("REGION")
+		assert.NoError(t, err)
+		assert.Equal(t, "eu-west-1", v)
+	})
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		v, err := vs.Get("")
+		assert.Equal(t, "", v)
+		assert.Equal(t, ErrVarNameEmpty, err)
+	})
+
+	t.Run("Where Var Does Not Exist", func(t *testing.T) {
+		v, err := vs.Get("TAG")
+		assert.Equal(t, "", v)
+		assert.Equal(t, ErrVarNotFound, err)
+	})
+}
+
+func TestVarSet_Remove(t *testing.T) {
+	vs := VarSet{"REGION": "eu-west-1"}
+
+	t.Run("Given Empty Name", func(t *testing.T) {
+		err := vs.Remove("")
+		assert.Equal(t, ErrVarNameEmpty, err)
+	})
+
+	t.Run("Where Var Does Not Exist", func(t *testing.T) {
+		err := vs.Remove("TAG")
+		assert.Equal(t, ErrVarNotFound, err)
+	})
+
+	t.Run("Given Valid Name", func(t *testing.T) {
+		err := vs.Remove("REGION")
+		assert.NoError(t, err)
+		assert.Empty(t, vs)
+	})
+}
+
+func TestVarSet_Names(t *testing.T) {
+	vs := VarSet{"TAG": "latest", "REGION": "eu-west-1"}
+	assert.Equal(t, []string{"REGION", "TAG"}, vs.Names())
+}
+
+func TestWorkspaceScript_Run_WithVars(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cnf := &Config{
+		Vars: VarSet{"registry": "global.io", "region": "eu-west-1", "tag": "global"},
+	}
+	w := &Workspace{
+		c:    cnf,
+		Vars: VarSet{"registry": "workspace.io", "tag": "workspace"},
+	}
+
+	run := func(t *testing.T, command string) (string, int, error) {
+		if os.Getenv("GOOS") != "linux" {
+			command = "cmd /C " + command
+		}
+
+		s := &WorkspaceScript{
+			c:       cnf,
+			w:       w,
+			Command: command,
+			Vars:    VarSet{"tag": "script"},
+		}
+
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			panic(err)
+		}
+
+		oldStdout := os.Stdout
+		os.Stdout = pw
+
+		t.Cleanup(func() {
+			pw.Close()
+			os.Stdout = oldStdout
+		})
+
+		code, err := s.Run(mock.NewMockCryptoProvider(ctrl))
+
+		pw.Close()
+		os.Stdout = oldStdout
+
+		bytes, _ := ioutil.ReadAll(pr)
+		return string(bytes), code, err
+	}
+
+	t.Run("Resolves Vars By Scope", func(t *testing.T) {
+		output, code, err := run(t, "echo <vars.tag> <vars.registry> <vars.region>")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, output, "script workspace.io eu-west-1")
+	})
+
+	t.Run("Resolves Env", func(t *testing.T) {
+		os.Setenv("PASSPORT_TEST_ENV", "hello")
+		t.Cleanup(func() {
+			os.Unsetenv("PASSPORT_TEST_ENV")
+		})
+
+		output, code, err := run(t, "echo <env.PASSPORT_TEST_ENV>")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, output, "hello")
+	})
+
+	t.Run("Resolves Defaults", func(t *testing.T) {
+		output, code, err := run(t, "echo <vars.missing:-fallback> <env.PASSPORT_TEST_MISSING:-none> <vars.tag:-unused>")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, code)
+		assert.Contains(t, output, "fallback none script")
+	})
+
+	t.Run("Where Var Does Not Exist", func(t *testing.T) {
+		_, code, err := run(t, "echo <vars.missing>")
+		assert.ErrorIs(t, err, ErrVarNotFound)
+		assert.Equal(t, -1, code)
+	})
+
+	t.Run("Where Env Does Not Exist", func(t *testing.T) {
+		_, code, err := run(t, "echo <env.PASSPORT_TEST_MISSING>")
+		assert.ErrorIs(t, err, ErrEnvNotFound)
+		assert.Equal(t, -1, code)
+	})
+}
@@ -0,0 +1,411 @@
+package passport
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Environment variables used to configure Vault, when the values
+// are not set in the config file.
+const (
+	VaultAddrEnvVar     = "VAULT_ADDR"
+	VaultTokenEnvVar    = "VAULT_TOKEN"
+	VaultSecretIDEnvVar = "VAULT_SECRET_ID"
+)
+
+const (
+	defaultVaultMount   = "secret"
+	defaultVaultTimeout = 30 * time.Second
+)
+
+// Common Vault errors.
+var (
+	ErrVaultAddressEmpty = errors.New("vault: address is empty")
+	ErrVaultPathEmpty    = errors.New("vault: path is empty")
+	ErrVaultAuthEmpty    = errors.New("vault: no token or approle credentials were given")
+	ErrVaultSecretSecure = errors.New("vault: secrets are encrypted by vault, so must be added as plain text")
+
+	// ErrVaultSecretUnsupported is returned when a secret has fields, other
+	// than its value, which can't be kept in Vault, i.e. a source or expiry.
+	ErrVaultSecretUnsupported = errors.New("vault: only the values of secrets can be kept in vault")
+)
+
+// VaultConfig configures a SecretStore which keeps secrets in a single
+// secret, at Path, in a HashiCorp Vault KV v2 secrets engine. Each key of
+// the Vault secret is the name of a secret. Either a token, or AppRole
+// credentials are used to authenticate.
+type VaultConfig struct {
+	// Address is the URL of the Vault server. If empty, VAULT_ADDR is used.
+	Address string `yaml:"address,omitempty"`
+	// Namespace is the, optional, Vault Enterprise namespace.
+	Namespace string `yaml:"namespace,omitempty"`
+	// Mount is the path the KV v2 engine is mounted at. Defaults to "secret".
+	Mount string `yaml:"mount,omitempty"`
+	Path  string `yaml:"path"`
+
+	// Token is used to authenticate. If empty, VAULT_TOKEN is used.
+	Token string `yaml:"token,omitempty"`
+	// RoleID and SecretID are used to authenticate using AppRole, if
+	// no token is given. If SecretID is empty, VAULT_SECRET_ID is used.
+	RoleID   string `yaml:"role_id,omitempty"`
+	SecretID string `yaml:"secret_id,omitempty"`
+}
+
+// Validate checks the config has an address, path and credentials.
+func (vc *VaultConfig) Validate() error {
+	switch {
+	case vc.address() == "":
+		return ErrVaultAddressEmpty
+	case strings.Trim(vc.Path, "/") == "":
+		return ErrVaultPathEmpty
+	case vc.token() == "" && (vc.RoleID == "" || vc.secretID() == ""):
+		return ErrVaultAuthEmpty
+	}
+
+	return nil
+}
+
+func (vc *VaultConfig) address() string {
+	if vc.Address != "" {
+		return strings.TrimSuffix(vc.Address, "/")
+	}
+
+	return strings.TrimSuffix(os.Getenv(VaultAddrEnvVar), "/")
+}
+
+func (vc *VaultConfig) mount() string {
+	if vc.Mount != "" {
+		return strings.Trim(vc.Mount, "/")
+	}
+
+	return defaultVaultMount
+}
+
+func (vc *VaultConfig) token() string {
+	if vc.Token != "" {
+		return vc.Token
+	}
+
+	if vc.RoleID != "" {
+		return ""
+	}
+
+	return os.Getenv(VaultTokenEnvVar)
+}
+
+func (vc *VaultConfig) secretID() string {
+	if vc.SecretID != "" {
+		return vc.SecretID
+	}
+
+	return os.Getenv(VaultSecretIDEnvVar)
+}
+
+// vaultSecretStore is a SecretStore which keeps secrets in Vault. The
+// Vault secret is read once, then cached for the life of the store.
+type vaultSecretStore struct {
+	conf   *VaultConfig
+	client *http.Client
+
+	token   string
+	data    map[string]string
+	version int
+}
+
+// NewVaultSecretStore returns a new SecretStore, which keeps secrets
+// in Vault, as configured by conf. If client is nil, a default client
+// is used.
+func NewVaultSecretStore(conf *VaultConfig, client *http.Client) SecretStore {
+	if client == nil {
+		client = &http.Client{Timeout: defaultVaultTimeout}
+	}
+
+	return &vaultSecretStore{
+		conf:   conf,
+		client: client,
+	}
+}
+
+// Get returns the secret with the given name, from the Vault secret.
+func (s *vaultSecretStore) Get(name string) (*Secret, error) {
+	err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := s.data[name]
+	if !ok {
+		return nil, ErrSecretNotFound
+	}
+
+	return &Secret{Name: name, Value: v}, nil
+}
+
+// Put sets the secret in the Vault secret, creating a new version of it.
+// As Vault encrypts secrets, secure secrets are not supported. Only the
+// value of a secret is kept, so if the secret has a source, history, expiry
+// or policy, ErrVaultSecretUnsupported is returned.
+func (s *vaultSecretStore) Put(secret *Secret) error {
+	if secret.Secure {
+		return ErrVaultSecretSecure
+	}
+
+	if fields := unsupportedVaultFields(secret); len(fields) > 0 {
+		return fmt.Errorf("%w, not its %s: %s", ErrVaultSecretUnsupported, strings.Join(fields, ", "), secret.Name)
+	}
+
+	err := s.read()
+	if err != nil {
+		return err
+	}
+
+	data := s.copyData()
+	data[secret.Name] = secret.Value
+
+	return s.write(data)
+}
+
+// Delete removes the secret with the given name from the Vault
+// secret, creating a new version of it.
+func (s *vaultSecretStore) Delete(name string) error {
+	err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := s.data[name]; !ok {
+		return ErrSecretNotFound
+	}
+
+	data := s.copyData()
+	delete(data, name)
+
+	return s.write(data)
+}
+
+// List returns the secrets in the Vault secret, ordered by name.
+func (s *vaultSecretStore) List() ([]*Secret, error) {
+	err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(s.data))
+	for name := range s.data {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	secrets := make([]*Secret, len(names))
+	for i, name := range names {
+		secrets[i] = &Secret{Name: name, Value: s.data[name]}
+	}
+
+	return secrets, nil
+}
+
+// keepsHistory returns false, as Vault versions secrets itself.
+func (s *vaultSecretStore) keepsHistory() bool {
+	return false
+}
+
+// unsupportedVaultFields returns the names of the fields of the secret
+// which are set, but can't be kept in Vault.
+func unsupportedVaultFields(secret *Secret) []string {
+	var fields []string
+	for _, f := range []struct {
+		name string
+		set  bool
+	}{
+		{"source", secret.Source != "" || secret.Timeout != ""},
+		{"history", len(secret.History) > 0},
+		{"expiry", !secret.ExpiresAt.IsZero() || secret.RotateEvery != ""},
+		{"policy", len(secret.Allow) > 0 || secret.Confirm},
+	} {
+		if f.set {
+			fields = append(fields, f.name)
+		}
+	}
+
+	return fields
+}
+
+func (s *vaultSecretStore) copyData() map[string]string {
+	data := make(map[string]string, len(s.data))
+	for k, v := range s.data {
+		data[k] = v
+	}
+
+	return data
+}
+
+// read reads the Vault secret, if it has not already been read. If the
+// secret does not exist, the store is empty.
+func (s *vaultSecretStore) read() error {
+	if s.data != nil {
+		return nil
+	}
+
+	var resp struct {
+		Data struct {
+			Data     map[string]interface{} `json:"data"`
+			Metadata struct {
+				Version int `json:"version"`
+			} `json:"metadata"`
+		} `json:"data"`
+	}
+
+	status, err := s.do(http.MethodGet, s.dataURL(), nil, &resp)
+	if err != nil && status != http.StatusNotFound {
+		return err
+	}
+
+	s.data = make(map[string]string, len(resp.Data.Data))
+	for k, v := range resp.Data.Data {
+		s.data[k] = fmt.Sprint(v)
+	}
+
+	s.version = resp.Data.Metadata.Version
+
+	return nil
+}
+
+// write writes data as a new version of the Vault secret. The version
+// last read is used for check-and-set, so concurrent changes aren't lost.
+func (s *vaultSecretStore) write(data map[string]string) error {
+	req := map[string]interface{}{
+		"data": data,
+		"options": map[string]interface{}{
+			"cas": s.version,
+		},
+	}
+
+	var resp struct {
+		Data struct {
+			Version int `json:"version"`
+		} `json:"data"`
+	}
+
+	_, err := s.do(http.MethodPost, s.dataURL(), req, &resp)
+	if err != nil {
+		return err
+	}
+
+	s.data = data
+	s.version = resp.Data.Version
+
+	return nil
+}
+
+func (s *vaultSecretStore) dataURL() string {
+	return fmt.Sprintf("%s/v1/%s/data/%s", s.conf.address(), s.conf.mount(), strings.Trim(s.conf.Path, "/"))
+}
+
+// login returns a token used to authenticate requests, logging in
+// using AppRole, if a token was not given.
+func (s *vaultSecretStore) login() (string, error) {
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	err := s.conf.Validate()
+	if err != nil {
+		return "", err
+	}
+
+	s.token = s.conf.token()
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	req := map[string]string{
+		"role_id":   s.conf.RoleID,
+		"secret_id": s.conf.secretID(),
+	}
+
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+
+	_, err = s.send(http.MethodPost, s.conf.address()+"/v1/auth/approle/login", "", req, &resp)
+	if err != nil {
+		return "", err
+	}
+
+	s.token = resp.Auth.ClientToken
+
+	return s.token, nil
+}
+
+// do sends an authenticated request to Vault, returning the status code.
+func (s *vaultSecretStore) do(method, url string, body, out interface{}) (int, error) {
+	token, err := s.login()
+	if err != nil {
+		return 0, err
+	}
+
+	return s.send(method, url, token, body, out)
+}
+
+// send sends a request to Vault, decoding the response into out. If the
+// response is not successful, an error containing Vault's errors is returned.
+func (s *vaultSecretStore) send(method, url, token string, body, out interface{}) (int, error) {
+	var r io.Reader
+	if body != nil {
+		data, _ := json.Marshal(body)
+		r = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, r)
+	if err != nil {
+		return 0, err
+	}
+
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+
+	if s.conf.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.conf.Namespace)
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("vault: request failed: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+
+		return resp.StatusCode, fmt.Errorf("vault: %s %s returned %d: %s", method, url, resp.StatusCode, strings.Join(e.Errors, ", "))
+	}
+
+	if out != nil && resp.StatusCode != http.StatusNoContent {
+		err = json.NewDecoder(resp.Body).Decode(out)
+		if err != nil {
+			return resp.StatusCode, fmt.Errorf("vault: failed to decode response: %w", err)
+		}
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,359 @@
+package passport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/reecerussell/passport/mock"
+)
+
+// testVaultServer is a stand-in for a Vault server, with a KV v2 engine
+// mounted at "secret", which accepts the token "s.token" and the AppRole
+// credentials "role"/"secret".
+type testVaultServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	data     map[string]map[string]interface{}
+	versions map[string]int
+	requests int
+	logins   int
+}
+
+func newTestVaultServer(t *testing.T) *testVaultServer {
+	s := &testVaultServer{
+		data:     make(map[string]map[string]interface{}),
+		versions: make(map[string]int),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *testVaultServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	writeErr := func(status int, msg string) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{msg}})
+	}
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["role_id"] != "role" || req["secret_id"] != "secret" {
+			writeErr(http.StatusBadRequest, "invalid role or secret ID")
+			return
+		}
+
+		s.logins++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"auth": map[string]interface{}{"client_token": "s.token"},
+		})
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != "s.token" {
+		writeErr(http.StatusForbidden, "permission denied")
+		return
+	}
+
+	const prefix = "/v1/secret/data/"
+	if len(r.URL.Path) <= len(prefix) || r.URL.Path[:len(prefix)] != prefix {
+		writeErr(http.StatusNotFound, "no handler for route")
+		return
+	}
+
+	p := r.URL.Path[len(prefix):]
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := s.data[p]
+		if !ok {
+			writeErr(http.StatusNotFound, "")
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"data":     data,
+				"metadata": map[string]interface{}{"version": s.versions[p]},
+			},
+		})
+	case http.MethodPost:
+		var req struct {
+			Data    map[string]interface{} `json:"data"`
+			Options struct {
+				CAS int `json:"cas"`
+			} `json:"options"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Options.CAS != s.versions[p] {
+			writeErr(http.StatusBadRequest, "check-and-set parameter did not match the current version")
+			return
+		}
+
+		s.data[p] = req.Data
+		s.versions[p]++
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"version": s.versions[p]},
+		})
+	default:
+		writeErr(http.StatusMethodNotAllowed, "")
+	}
+}
+
+func TestVaultSecretStore(t *testing.T) {
+	t.Run("Get Using Token", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+		srv.data["app"] = map[string]interface{}{"Token": "abc", "Port": 8080}
+		srv.versions["app"] = 1
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client())
+		s, err := store.Get("Token")
+		assert.NoError(t, err)
+		assert.Equal(t, &Secret{Name: "Token", Value: "abc"}, s)
+
+		s, err = store.Get("Port")
+		assert.NoError(t, err)
+		assert.Equal(t, "8080", s.Value)
+
+		s, err = store.Get("Other")
+		assert.Nil(t, s)
+		assert.Equal(t, ErrSecretNotFound, err)
+
+		// the secret is only read once, as it's cached.
+		assert.Equal(t, 1, srv.requests)
+	})
+
+	t.Run("Get Using AppRole", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+		srv.data["app"] = map[string]interface{}{"Token": "abc"}
+		srv.versions["app"] = 1
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "/app/", RoleID: "role", SecretID: "secret"}, srv.Client())
+		s, err := store.Get("Token")
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", s.Value)
+		assert.Equal(t, 1, srv.logins)
+	})
+
+	t.Run("Where AppRole Login Fails", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", RoleID: "role", SecretID: "wrong"}, srv.Client())
+		s, err := store.Get("Token")
+		assert.Nil(t, s)
+		assert.Contains(t, err.Error(), "invalid role or secret ID")
+	})
+
+	t.Run("Where Token Is Invalid", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "wrong"}, srv.Client())
+		s, err := store.Get("Token")
+		assert.Nil(t, s)
+		assert.Contains(t, err.Error(), "403: permission denied")
+	})
+
+	t.Run("Where Secret Does Not Exist", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client())
+		secrets, err := store.List()
+		assert.NoError(t, err)
+		assert.Empty(t, secrets)
+	})
+
+	t.Run("Put, Delete And List", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client())
+		assert.NoError(t, store.Put(&Secret{Name: "B", Value: "2"}))
+		assert.NoError(t, store.Put(&Secret{Name: "A", Value: "1"}))
+		assert.Equal(t, map[string]interface{}{"A": "1", "B": "2"}, srv.data["app"])
+		assert.Equal(t, 2, srv.versions["app"])
+
+		secrets, err := store.List()
+		assert.NoError(t, err)
+		assert.Equal(t, []*Secret{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}, secrets)
+
+		assert.NoError(t, store.Delete("B"))
+		assert.Equal(t, map[string]interface{}{"A": "1"}, srv.data["app"])
+		assert.Equal(t, ErrSecretNotFound, store.Delete("B"))
+	})
+
+	t.Run("Where Secret Was Changed Concurrently", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client())
+		_, err := store.List()
+		assert.NoError(t, err)
+
+		srv.data["app"] = map[string]interface{}{"Other": "value"}
+		srv.versions["app"] = 1
+
+		err = store.Put(&Secret{Name: "A", Value: "1"})
+		assert.Contains(t, err.Error(), "check-and-set")
+	})
+
+	t.Run("Put Secure Secret", func(t *testing.T) {
+		store := NewVaultSecretStore(&VaultConfig{Address: "http://localhost", Path: "app", Token: "s.token"}, nil)
+		err := store.Put(&Secret{Name: "A", Value: "1", Secure: true})
+		assert.Equal(t, ErrVaultSecretSecure, err)
+	})
+
+	t.Run("Put Secret With Unsupported Fields", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		store := NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client())
+		err := store.Put(&Secret{
+			Name:        "A",
+			Value:       "1",
+			RotateEvery: "90d",
+			Allow:       []*SecretAccess{{Workspace: "app"}},
+		})
+		assert.True(t, errors.Is(err, ErrVaultSecretUnsupported))
+		assert.Equal(t, "vault: only the values of secrets can be kept in vault, not its expiry, policy: A", err.Error())
+		assert.Nil(t, srv.data["app"])
+	})
+
+	t.Run("Config Functions Using Vault", func(t *testing.T) {
+		srv := newTestVaultServer(t)
+
+		c := &Config{}
+		c.SetSecretStore(NewVaultSecretStore(&VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"}, srv.Client()))
+		assert.NoError(t, c.AddSecret("A", "1", false, nil))
+		assert.NoError(t, c.SetSecret("A", "2", false, nil))
+		assert.Equal(t, map[string]interface{}{"A": "2"}, srv.data["app"])
+
+		err := c.AllowSecret("A", "app", "")
+		assert.True(t, errors.Is(err, ErrVaultSecretUnsupported))
+
+		err = c.SetSecretConfirm("A", true)
+		assert.True(t, errors.Is(err, ErrVaultSecretUnsupported))
+
+		err = c.SetSecretExpiry("A", time.Time{}, "90d")
+		assert.True(t, errors.Is(err, ErrVaultSecretUnsupported))
+
+		err = c.AddSecretSource("B", "file:~/token", "")
+		assert.True(t, errors.Is(err, ErrVaultSecretUnsupported))
+	})
+}
+
+func TestVaultConfig_Validate(t *testing.T) {
+	t.Setenv(VaultAddrEnvVar, "")
+	t.Setenv(VaultTokenEnvVar, "")
+	t.Setenv(VaultSecretIDEnvVar, "")
+
+	t.Run("Given Valid Token Config", func(t *testing.T) {
+		vc := &VaultConfig{Address: "http://localhost", Path: "app", Token: "s.token"}
+		assert.NoError(t, vc.Validate())
+	})
+
+	t.Run("Given Empty Address", func(t *testing.T) {
+		vc := &VaultConfig{Path: "app", Token: "s.token"}
+		assert.Equal(t, ErrVaultAddressEmpty, vc.Validate())
+	})
+
+	t.Run("Given Empty Path", func(t *testing.T) {
+		vc := &VaultConfig{Address: "http://localhost", Path: "/", Token: "s.token"}
+		assert.Equal(t, ErrVaultPathEmpty, vc.Validate())
+	})
+
+	t.Run("Given No Credentials", func(t *testing.T) {
+		vc := &VaultConfig{Address: "http://localhost", Path: "app", RoleID: "role"}
+		assert.Equal(t, ErrVaultAuthEmpty, vc.Validate())
+	})
+
+	t.Run("Uses Environment Variables", func(t *testing.T) {
+		t.Setenv(VaultAddrEnvVar, "http://localhost")
+		t.Setenv(VaultSecretIDEnvVar, "secret")
+
+		vc := &VaultConfig{Path: "app", RoleID: "role"}
+		assert.NoError(t, vc.Validate())
+	})
+}
+
+func TestConfig_SecretStore_Vault(t *testing.T) {
+	srv := newTestVaultServer(t)
+	srv.data["app"] = map[string]interface{}{"Token": "abc"}
+	srv.versions["app"] = 1
+
+	c := &Config{
+		Vault: &VaultConfig{Address: srv.URL, Path: "app", Token: "s.token"},
+		Workspaces: []*Workspace{
+			{
+				Name: "app",
+				Path: "/c/app",
+				Scripts: []*WorkspaceScript{
+					{Name: "build", Command: "echo <secrets.Token> <secrets.Token>"},
+				},
+			},
+		},
+	}
+
+	w, _ := c.GetWorkspace("/c/app")
+	s, _ := w.GetScript("build")
+
+	args, err := s.commandArgs(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"echo", "abc", "abc"}, args)
+	assert.Equal(t, 1, srv.requests)
+}
+
+func TestConfig_Update_Vault(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	srv := newTestVaultServer(t)
+	srv.data["app"] = map[string]interface{}{"Token": "app"}
+	srv.versions["app"] = 1
+	srv.data["api"] = map[string]interface{}{"Token": "api"}
+	srv.versions["api"] = 1
+
+	testDir := "config"
+	testData := func(vaultPath string) []byte {
+		return []byte(fmt.Sprintf("version: 1\nvault:\n  address: %s\n  path: %s\n  token: s.token\n", srv.URL, vaultPath))
+	}
+
+	fs := mock.NewMockFilesys(ctrl)
+	fs.EXPECT().Lock(gomock.Any()).Return(func() error { return nil }, nil)
+	fs.EXPECT().Read(path.Join(testDir, configFilename)).Return(testData("api"), nil)
+	fs.EXPECT().FileExists(gomock.Any()).Return(false, nil)
+	fs.EXPECT().Write(gomock.Any(), gomock.Any()).Return(nil)
+
+	c := &Config{configDir: testDir, fs: fs}
+	assert.NoError(t, yaml.Unmarshal(testData("app"), c))
+
+	sec, err := c.GetSecret("Token")
+	assert.NoError(t, err)
+	assert.Equal(t, "app", sec.Value)
+
+	// The config is reloaded by Update, where the Vault path has changed.
+	err = c.Update(func(c *Config) error {
+		sec, err := c.GetSecret("Token")
+		if err != nil {
+			return err
+		}
+
+		assert.Equal(t, "api", sec.Value)
+		return nil
+	})
+	assert.NoError(t, err)
+}